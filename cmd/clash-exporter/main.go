@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func splitTags(s string) []string {
+	if s == "" {
+		return nil
+	}
+	tags := strings.Split(s, ",")
+	for i := range tags {
+		tags[i] = strings.TrimSpace(tags[i])
+	}
+	return tags
+}
+
+func main() {
+	var (
+		listen  = flag.String("listen", ":9101", "address to serve metrics on")
+		token   = flag.String("token", os.Getenv("CLASH_TOKEN"), "Clash of Clans API token")
+		clans   = flag.String("clans", "", "comma separated clan tags to export")
+		players = flag.String("players", "", "comma separated player tags to export")
+		cache   = flag.Duration("cache", metrics.DefaultCacheTTL, "how long to cache API responses")
+		timeout = flag.Duration("timeout", 10*time.Second, "API request timeout")
+		verbose = flag.Bool("v", false, "log API requests")
+	)
+	flag.Parse()
+
+	if *token == "" {
+		log.Fatal("no API token was given, set -token or CLASH_TOKEN")
+	}
+	if *clans == "" && *players == "" {
+		log.Fatal("no clan or player tags were given")
+	}
+
+	client, err := goclash.NewClient(*token)
+	if err != nil {
+		log.Fatal(err)
+	}
+	client.SetTimeout(*timeout)
+	if !*verbose {
+		client.SetLogger(log.New(ioutil.Discard, "", 0))
+	}
+
+	collector := metrics.NewCollector(client, splitTags(*clans), splitTags(*players), *cache)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	http.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	log.Printf("serving metrics on %s/metrics", *listen)
+	log.Fatal(http.ListenAndServe(*listen, nil))
+}
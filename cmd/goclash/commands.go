@@ -0,0 +1,394 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/war"
+)
+
+// result is the outcome of a command. Value is encoded for json, yaml, csv and
+// xlsx output while table writes a human readable table
+type result struct {
+	value  interface{}
+	table  func(w io.Writer) error
+	fields []string
+}
+
+// command is a single service action that can be run from the command line
+type command struct {
+	usage   string
+	summary string
+	args    int
+	run     func(client *goclash.Client, ctrl *goclash.Control, args []string) (*result, error)
+}
+
+var commands = map[string]*command{
+	"clan search": {
+		usage: "<name>", summary: "search for clans by name", args: 1, run: clanSearch},
+	"clan get": {
+		usage: "<clan tag>", summary: "get a clan", args: 1, run: clanGet},
+	"clan members": {
+		usage: "<clan tag>", summary: "list the members of a clan", args: 1, run: clanMembers},
+	"clan warlog": {
+		usage: "<clan tag>", summary: "list a clans war log", args: 1, run: clanWarLog},
+	"clan war": {
+		usage: "<clan tag>", summary: "get a clans current war", args: 1, run: clanWar},
+	"clan leaguegroup": {
+		usage: "<clan tag>", summary: "get a clans war league group", args: 1, run: clanLeagueGroup},
+	"player get": {
+		usage: "<player tag>", summary: "get a player", args: 1, run: playerGet},
+	"league list": {
+		summary: "list leagues", run: leagueList},
+	"league seasons": {
+		usage: "<league id>", summary: "list legend league seasons", args: 1, run: leagueSeasons},
+	"league rankings": {
+		usage: "<league id> <season id>", summary: "list legend season rankings", args: 2,
+		run: leagueRankings},
+	"location list": {
+		summary: "list locations", run: locationList},
+	"location rankings": {
+		usage:   "<location id, country code or name> [clans|players|clans-versus|players-versus]",
+		summary: "list rankings in a location", args: 1, run: locationRankings},
+	"label list": {
+		usage: "[clans|players]", summary: "list clan or player labels", run: labelList},
+}
+
+// execute will parse the action flags and run the command
+func (c *command) execute(client *goclash.Client, args []string) (*result, error) {
+	var ctrl goclash.Control
+	fs := flag.NewFlagSet("action", flag.ContinueOnError)
+	fs.IntVar(&ctrl.Limit, "limit", 0, "limit the amount of items returned")
+	fs.StringVar(&ctrl.After, "after", "", "return items after this cursor")
+	fs.StringVar(&ctrl.Before, "before", "", "return items before this cursor")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if fs.NArg() < c.args {
+		return nil, fmt.Errorf("missing arguments, usage: %s", c.usage)
+	}
+
+	return c.run(client, &ctrl, fs.Args())
+}
+
+func parseId(s string) (int32, error) {
+	id, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid id %q: %s", s, err.Error())
+	}
+	return int32(id), nil
+}
+
+func itoa(i int) string {
+	return strconv.Itoa(i)
+}
+
+func clanSearch(client *goclash.Client, ctrl *goclash.Control, args []string) (*result, error) {
+	clans, err := client.Clan.Search(args[0], &goclash.ClanSearchOptions{Control: *ctrl})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result{value: clans, table: func(w io.Writer) error {
+		rows := make([][]string, 0, len(clans))
+		for _, c := range clans {
+			rows = append(rows, []string{c.Tag, c.Name, itoa(c.Level), itoa(c.MemberCount),
+				strconv.Itoa(int(c.Points)), c.Location.Name})
+		}
+		return writeTable(w, []string{"Tag", "Name", "Level", "Members", "Points", "Location"}, rows)
+	}}, nil
+}
+
+func clanGet(client *goclash.Client, _ *goclash.Control, args []string) (*result, error) {
+	clan, err := client.Clan.Get(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &result{value: clan, table: func(w io.Writer) error {
+		return writeFields(w, [][]string{
+			{"Tag", clan.Tag},
+			{"Name", clan.Name},
+			{"Type", clan.Type},
+			{"Location", clan.Location.Name},
+			{"Level", itoa(clan.Level)},
+			{"Points", strconv.Itoa(int(clan.Points))},
+			{"Versus Points", strconv.Itoa(int(clan.VersusPoints))},
+			{"Members", itoa(clan.MemberCount)},
+			{"Required Trophies", itoa(clan.RequiredTrophies)},
+			{"War Frequency", clan.WarFrequency},
+			{"War Record", fmt.Sprintf("%d-%d-%d", clan.WarWins, clan.WarTies, clan.WarLosses)},
+			{"War Win Streak", itoa(clan.WarWinStreak)},
+			{"War Log Public", strconv.FormatBool(clan.IsWarLogPublic)},
+		})
+	}}, nil
+}
+
+func clanMembers(client *goclash.Client, ctrl *goclash.Control, args []string) (*result, error) {
+	members, err := client.Clan.GetMembers(args[0], ctrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result{value: members, table: func(w io.Writer) error {
+		rows := make([][]string, 0, len(members))
+		for _, m := range members {
+			rows = append(rows, []string{itoa(m.Rank), m.Tag, m.Name, m.Role, itoa(m.ExpLevel),
+				itoa(m.Trophies), itoa(m.Donations), itoa(m.DonationsReceived)})
+		}
+		return writeTable(w, []string{"Rank", "Tag", "Name", "Role", "Level", "Trophies", "Donated",
+			"Received"}, rows)
+	}}, nil
+}
+
+func clanWarLog(client *goclash.Client, ctrl *goclash.Control, args []string) (*result, error) {
+	logs, err := client.Clan.GetWarLogs(args[0], ctrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result{value: logs, table: func(w io.Writer) error {
+		rows := make([][]string, 0, len(logs))
+		for _, l := range logs {
+			rows = append(rows, []string{l.EndTime.Format("2006-01-02"), l.Result, itoa(l.TeamSize),
+				fmt.Sprintf("%d-%d", l.Clan.Stars, l.OpponentClan.Stars),
+				fmt.Sprintf("%.2f-%.2f", l.Clan.DestructionPercentage, l.OpponentClan.DestructionPercentage),
+				l.OpponentClan.Name})
+		}
+		return writeTable(w, []string{"End", "Result", "Size", "Stars", "Destruction", "Opponent"}, rows)
+	}}, nil
+}
+
+func clanWar(client *goclash.Client, _ *goclash.Control, args []string) (*result, error) {
+	currentWar, err := client.Clan.GetCurrentWar(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &result{value: currentWar, table: func(w io.Writer) error {
+		if _, err := fmt.Fprintf(w, "%s vs %s (%s) %d-%d\n\n", currentWar.Clan.Name,
+			currentWar.OpponentClan.Name, currentWar.State, currentWar.Clan.Stars,
+			currentWar.OpponentClan.Stars); err != nil {
+			return err
+		}
+		return war.NewReport(currentWar).Clan.WriteTable(w)
+	}}, nil
+}
+
+func clanLeagueGroup(client *goclash.Client, _ *goclash.Control, args []string) (*result, error) {
+	group, err := client.Clan.GetLeagueGroup(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &result{value: group, table: func(w io.Writer) error {
+		rows := make([][]string, 0, len(group.Clans))
+		for _, c := range group.Clans {
+			rows = append(rows, []string{c.Tag, c.Name, itoa(c.Level), itoa(len(c.Team))})
+		}
+		return writeTable(w, []string{"Tag", "Name", "Level", "Roster"}, rows)
+	}}, nil
+}
+
+func playerGet(client *goclash.Client, _ *goclash.Control, args []string) (*result, error) {
+	player, err := client.Player.Get(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &result{value: player, table: func(w io.Writer) error {
+		return writeFields(w, [][]string{
+			{"Tag", player.Tag},
+			{"Name", player.Name},
+			{"Level", itoa(player.ExpLevel)},
+			{"Town Hall", itoa(player.TownhallLevel)},
+			{"Builder Hall", itoa(player.BuilderHallLevel)},
+			{"Trophies", fmt.Sprintf("%d (best %d)", player.Trophies, player.BestTrophies)},
+			{"League", player.League.Name},
+			{"War Stars", itoa(player.WarStars)},
+			{"Attack Wins", itoa(player.AttackWins)},
+			{"Defence Wins", itoa(player.DefenceWins)},
+			{"Donations", fmt.Sprintf("%d/%d", player.Donations, player.DonationsReceived)},
+			{"Clan", fmt.Sprintf("%s %s", player.Clan.Tag, player.Clan.Name)},
+			{"Role", player.Role},
+		})
+	}}, nil
+}
+
+func leagueList(client *goclash.Client, ctrl *goclash.Control, _ []string) (*result, error) {
+	leagues, err := client.League.List(ctrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result{value: leagues, table: func(w io.Writer) error {
+		rows := make([][]string, 0, len(leagues))
+		for _, l := range leagues {
+			rows = append(rows, []string{strconv.Itoa(int(l.Id)), l.Name})
+		}
+		return writeTable(w, []string{"Id", "Name"}, rows)
+	}}, nil
+}
+
+func leagueSeasons(client *goclash.Client, ctrl *goclash.Control, args []string) (*result, error) {
+	leagueId, err := parseId(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	seasons, err := client.League.GetSeasons(leagueId, ctrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result{value: seasons, table: func(w io.Writer) error {
+		rows := make([][]string, 0, len(seasons))
+		for _, s := range seasons {
+			rows = append(rows, []string{s.Id})
+		}
+		return writeTable(w, []string{"Season"}, rows)
+	}}, nil
+}
+
+func leagueRankings(client *goclash.Client, ctrl *goclash.Control, args []string) (*result, error) {
+	leagueId, err := parseId(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	players, err := client.League.GetSeasonRankings(leagueId, args[1], ctrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result{value: players, table: func(w io.Writer) error {
+		rows := make([][]string, 0, len(players))
+		for _, p := range players {
+			rows = append(rows, []string{itoa(p.Rank), p.Tag, p.Name, itoa(p.Trophies), p.Clan.Name})
+		}
+		return writeTable(w, []string{"Rank", "Tag", "Name", "Trophies", "Clan"}, rows)
+	}}, nil
+}
+
+func locationList(client *goclash.Client, ctrl *goclash.Control, _ []string) (*result, error) {
+	locations, err := client.Location.List(ctrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result{value: locations, table: func(w io.Writer) error {
+		rows := make([][]string, 0, len(locations))
+		for _, l := range locations {
+			rows = append(rows, []string{strconv.Itoa(int(l.Id)), l.Code, l.Name,
+				strconv.FormatBool(l.IsCountry)})
+		}
+		return writeTable(w, []string{"Id", "Code", "Name", "Country"}, rows)
+	}}, nil
+}
+
+func locationRankings(client *goclash.Client, ctrl *goclash.Control, args []string) (*result, error) {
+	locationId, err := parseId(args[0])
+	if err != nil {
+		location, err := goclash.NewLocations(client).Resolve(args[0])
+		if err != nil {
+			return nil, err
+		}
+		locationId = location.Id
+	}
+
+	kind := "clans"
+	if len(args) > 1 {
+		kind = args[1]
+	}
+
+	switch kind {
+	case "clans":
+		rankings, err := client.Location.GetClanRankings(locationId, ctrl)
+		if err != nil {
+			return nil, err
+		}
+		return &result{value: rankings, table: func(w io.Writer) error {
+			rows := make([][]string, 0, len(rankings))
+			for _, r := range rankings {
+				rows = append(rows, []string{itoa(r.Rank), r.Tag, r.Name, itoa(r.Level),
+					itoa(r.MemberCount), itoa(r.Points)})
+			}
+			return writeTable(w, []string{"Rank", "Tag", "Name", "Level", "Members", "Points"}, rows)
+		}}, nil
+	case "players":
+		rankings, err := client.Location.GetPlayerRankings(locationId, ctrl)
+		if err != nil {
+			return nil, err
+		}
+		return &result{value: rankings, table: func(w io.Writer) error {
+			rows := make([][]string, 0, len(rankings))
+			for _, r := range rankings {
+				rows = append(rows, []string{itoa(r.Rank), r.Tag, r.Name, itoa(r.ExpLevel),
+					itoa(r.Trophies), r.Clan.Name})
+			}
+			return writeTable(w, []string{"Rank", "Tag", "Name", "Level", "Trophies", "Clan"}, rows)
+		}}, nil
+	case "clans-versus":
+		rankings, err := client.Location.GetClanVersusRankings(locationId, ctrl)
+		if err != nil {
+			return nil, err
+		}
+		return &result{value: rankings, table: func(w io.Writer) error {
+			rows := make([][]string, 0, len(rankings))
+			for i, r := range rankings {
+				rows = append(rows, []string{itoa(i + 1), itoa(r.Points), itoa(r.VersusPoints)})
+			}
+			return writeTable(w, []string{"#", "Points", "Versus Points"}, rows)
+		}}, nil
+	case "players-versus":
+		rankings, err := client.Location.GetPlayerVersusRankings(locationId, ctrl)
+		if err != nil {
+			return nil, err
+		}
+		return &result{value: rankings, table: func(w io.Writer) error {
+			rows := make([][]string, 0, len(rankings))
+			for _, r := range rankings {
+				rows = append(rows, []string{itoa(r.Rank), r.Tag, r.Name, itoa(r.Trophies), r.Clan.Name})
+			}
+			return writeTable(w, []string{"Rank", "Tag", "Name", "Trophies", "Clan"}, rows)
+		}}, nil
+	}
+
+	return nil, fmt.Errorf("unknown ranking type %q", kind)
+}
+
+func labelList(client *goclash.Client, ctrl *goclash.Control, args []string) (*result, error) {
+	var (
+		labels []*goclash.Label
+		err    error
+	)
+
+	kind := "clans"
+	if len(args) > 0 {
+		kind = args[0]
+	}
+
+	switch kind {
+	case "clans":
+		labels, err = client.Label.ClanList(ctrl)
+	case "players":
+		labels, err = client.Label.PlayerList(ctrl)
+	default:
+		return nil, fmt.Errorf("unknown label type %q", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &result{value: labels, table: func(w io.Writer) error {
+		rows := make([][]string, 0, len(labels))
+		for _, l := range labels {
+			rows = append(rows, []string{strconv.Itoa(int(l.Id)), l.Name})
+		}
+		return writeTable(w, []string{"Id", "Name"}, rows)
+	}}, nil
+}
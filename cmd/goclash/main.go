@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+)
+
+const usageHeader = `goclash queries the Clash of Clans API from the command line
+
+Usage:
+  goclash [flags] <service> <action> [action flags] [arguments]
+
+The API token is read from the -token flag, the CLASH_TOKEN environment variable
+or the "token" key of the JSON config file, in that order.
+
+Flags:
+`
+
+// options are the flags shared by every command
+type options struct {
+	token   string
+	config  string
+	output  string
+	fields  string
+	timeout time.Duration
+	verbose bool
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("goclash: ")
+
+	var opts options
+	flag.StringVar(&opts.token, "token", "", "Clash of Clans API token")
+	flag.StringVar(&opts.config, "config", defaultConfigPath(), "path to the config file")
+	flag.StringVar(&opts.output, "o", "table", "output format: table, json, yaml, csv or xlsx")
+	flag.StringVar(&opts.fields, "fields", "", "comma separated fields to include in csv or xlsx output")
+	flag.DurationVar(&opts.timeout, "timeout", 10*time.Second, "request timeout")
+	flag.BoolVar(&opts.verbose, "v", false, "log requests to stderr")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[flag.Arg(0)+" "+flag.Arg(1)]
+	if !ok {
+		log.Printf("unknown command %q", strings.Join(flag.Args()[:2], " "))
+		usage()
+		os.Exit(2)
+	}
+
+	encode, ok := encoders[opts.output]
+	if !ok {
+		log.Fatalf("unknown output format %q", opts.output)
+	}
+
+	client, err := newClient(&opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	res, err := cmd.execute(client, flag.Args()[2:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if opts.fields != "" {
+		res.fields = strings.Split(opts.fields, ",")
+	}
+
+	if err = encode(os.Stdout, res); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprint(out, usageHeader)
+	flag.PrintDefaults()
+	fmt.Fprint(out, "\nCommands:\n")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-40s %s\n", name+" "+commands[name].usage, commands[name].summary)
+	}
+}
+
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "goclash", "config.json")
+}
+
+// config is the layout of the config file
+type config struct {
+	Token string `json:"token"`
+}
+
+func readConfig(path string) (*config, error) {
+	var cfg config
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &cfg, nil
+		}
+		return nil, fmt.Errorf("could not read config file: %s", err.Error())
+	}
+	if err = json.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("could not decode config file %s: %s", path, err.Error())
+	}
+	return &cfg, nil
+}
+
+func newClient(opts *options) (*goclash.Client, error) {
+	token := opts.token
+	if token == "" {
+		token = os.Getenv("CLASH_TOKEN")
+	}
+	if token == "" && opts.config != "" {
+		cfg, err := readConfig(opts.config)
+		if err != nil {
+			return nil, err
+		}
+		token = cfg.Token
+	}
+	if token == "" {
+		return nil, fmt.Errorf("no API token was given, set -token, CLASH_TOKEN or the config file")
+	}
+
+	client, err := goclash.NewClient(token)
+	if err != nil {
+		return nil, err
+	}
+	client.SetTimeout(opts.timeout)
+
+	logOut := ioutil.Discard
+	if opts.verbose {
+		logOut = os.Stderr
+	}
+	client.SetLogger(log.New(logOut, "[LIBCLASH] ", log.LstdFlags))
+
+	return client, nil
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/joshturge/goclash/pkg/export"
+)
+
+// encoders write a commands result to w in a specific output format
+var encoders = map[string]func(w io.Writer, res *result) error{
+	"table": encodeTable,
+	"json":  encodeJSON,
+	"yaml":  encodeYAML,
+	"csv":   encodeCSV,
+	"xlsx":  encodeXLSX,
+}
+
+func encodeTable(w io.Writer, res *result) error {
+	return res.table(w)
+}
+
+func encodeJSON(w io.Writer, res *result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(res.value); err != nil {
+		return fmt.Errorf("could not encode json: %s", err.Error())
+	}
+	return nil
+}
+
+func exportTable(res *result) (*export.Table, error) {
+	table, err := export.New(res.value, res.fields...)
+	if err != nil {
+		return nil, fmt.Errorf("this command does not support csv or xlsx output: %s", err.Error())
+	}
+	return table, nil
+}
+
+func encodeCSV(w io.Writer, res *result) error {
+	table, err := exportTable(res)
+	if err != nil {
+		return err
+	}
+	return table.WriteCSV(w)
+}
+
+func encodeXLSX(w io.Writer, res *result) error {
+	table, err := exportTable(res)
+	if err != nil {
+		return err
+	}
+	return table.WriteXLSX(w)
+}
+
+func writeTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeFields(w io.Writer, fields [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, field := range fields {
+		fmt.Fprintf(tw, "%s:\t%s\n", field[0], field[1])
+	}
+	return tw.Flush()
+}
+
+// yamlNode is a decoded json value that keeps the order of object keys, so yaml
+// output lists fields in the same order as json output
+type yamlNode struct {
+	scalar string
+	keys   []string
+	values []*yamlNode
+	object bool
+	array  bool
+}
+
+// encodeYAML will encode the result as json and then translate it into yaml. This
+// keeps the json tags of the API types as the yaml keys
+func encodeYAML(w io.Writer, res *result) error {
+	b, err := json.Marshal(res.value)
+	if err != nil {
+		return fmt.Errorf("could not encode value: %s", err.Error())
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	node, err := decodeNode(dec)
+	if err != nil {
+		return fmt.Errorf("could not decode value: %s", err.Error())
+	}
+
+	var buf bytes.Buffer
+	if s, ok := node.inline(); ok {
+		buf.WriteString(s)
+		buf.WriteString("\n")
+	} else {
+		writeNode(&buf, node, 0)
+	}
+
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+func decodeNode(dec *json.Decoder) (*yamlNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		node := yamlNode{object: t == '{', array: t == '['}
+		for dec.More() {
+			if node.object {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.keys = append(node.keys, yamlString(keyTok.(string)))
+			}
+			value, err := decodeNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.values = append(node.values, value)
+		}
+		// consume the closing delimiter
+		if _, err = dec.Token(); err != nil {
+			return nil, err
+		}
+		return &node, nil
+	case string:
+		return &yamlNode{scalar: yamlString(t)}, nil
+	case json.Number:
+		return &yamlNode{scalar: t.String()}, nil
+	case bool:
+		return &yamlNode{scalar: fmt.Sprint(t)}, nil
+	case nil:
+		return &yamlNode{scalar: "null"}, nil
+	}
+
+	return nil, fmt.Errorf("unexpected json token %v", tok)
+}
+
+var plainYAML = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.\-/ ]*$`)
+
+// yamlString will quote s if it would not be read back as the same plain string
+func yamlString(s string) string {
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "y", "n":
+	default:
+		if plainYAML.MatchString(s) && !strings.HasSuffix(s, " ") {
+			return s
+		}
+	}
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// inline reports whether a node can be written on the same line as its key
+func (n *yamlNode) inline() (string, bool) {
+	switch {
+	case n.object && len(n.values) == 0:
+		return "{}", true
+	case n.array && len(n.values) == 0:
+		return "[]", true
+	case !n.object && !n.array:
+		return n.scalar, true
+	}
+	return "", false
+}
+
+func writeNode(buf *bytes.Buffer, n *yamlNode, indent int) {
+	pad := strings.Repeat("  ", indent)
+	for i, value := range n.values {
+		if n.object {
+			buf.WriteString(pad)
+			buf.WriteString(n.keys[i])
+			buf.WriteString(":")
+		} else {
+			buf.WriteString(pad)
+			buf.WriteString("-")
+		}
+
+		if s, ok := value.inline(); ok {
+			buf.WriteString(" ")
+			buf.WriteString(s)
+			buf.WriteString("\n")
+			continue
+		}
+
+		if n.array && value.object {
+			// the first key of an object in a list shares the line with the dash
+			var item bytes.Buffer
+			writeNode(&item, value, indent+1)
+			buf.WriteString(" ")
+			buf.WriteString(strings.TrimPrefix(item.String(), pad+"  "))
+			continue
+		}
+
+		buf.WriteString("\n")
+		writeNode(buf, value, indent+1)
+	}
+}
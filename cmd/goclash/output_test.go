@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/joshturge/goclash/pkg/clash"
+)
+
+func TestEncodeYAML(t *testing.T) {
+	members := []*goclash.Member{
+		{Tag: "#RQ8JLVQ", Name: "true", Role: "admin", League: goclash.League{Id: 29000022}},
+	}
+
+	var buf bytes.Buffer
+	if err := encodeYAML(&buf, &result{value: members}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `- tag: "#RQ8JLVQ"
+  name: "true"
+  role: admin
+  expLevel: 0
+  league:
+    id: 29000022
+    name: ""
+    iconUrls:
+      tiny: ""
+      small: ""
+      medium: ""
+  trophies: 0
+  versusTrophies: 0
+  clanRank: 0
+  previousClanRank: 0
+  donations: 0
+  donationsReceived: 0
+`
+	if buf.String() != want {
+		t.Errorf("Wanted:\n%s\nGot:\n%s", want, buf.String())
+	}
+}
+
+func TestEncodeYAMLValues(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{
+			name: "nested",
+			json: `{"a": {"b": [1, 2], "c": {"d": "x"}}, "e": [{"f": 1.5, "g": [true]}, [3, [4]]]}`,
+			want: `a:
+  b:
+    - 1
+    - 2
+  c:
+    d: x
+e:
+  - f: 1.5
+    g:
+      - true
+  -
+    - 3
+    -
+      - 4
+`,
+		},
+		{
+			name: "list of objects in an object",
+			json: `{"members": [{"tag": "#P2", "heroes": [{"name": "Barbarian King"}]}, {"tag": "#P8"}]}`,
+			want: `members:
+  - tag: "#P2"
+    heroes:
+      - name: Barbarian King
+  - tag: "#P8"
+`,
+		},
+		{
+			name: "empty values",
+			json: `{"a": "", "b": null, "c": {}, "d": [], "e": 0, "f": false, "g": [{}, []]}`,
+			want: `a: ""
+b: null
+c: {}
+d: []
+e: 0
+f: false
+g:
+  - {}
+  - []
+`,
+		},
+		{name: "empty list", json: `[]`, want: "[]\n"},
+		{name: "empty object", json: `{}`, want: "{}\n"},
+		{name: "scalar", json: `"#2PP"`, want: "\"#2PP\"\n"},
+		{
+			name: "quoting",
+			json: `{"colon": "a: b", "hash": "#tag", "comment": "a #b", "dash": "-dash", "bool": "true",
+				"yes": "Yes", "null": "null", "tilde": "~", "number": "123", "lines": "one\ntwo",
+				"space": "trailing ", "plain": "Clan Wars", "a: b": "key", "": "empty key"}`,
+			want: `colon: "a: b"
+hash: "#tag"
+comment: "a #b"
+dash: "-dash"
+bool: "true"
+"yes": "Yes"
+"null": "null"
+tilde: "~"
+number: "123"
+lines: "one\ntwo"
+space: "trailing "
+plain: Clan Wars
+"a: b": key
+"": empty key
+`,
+		},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := encodeYAML(&buf, &result{value: json.RawMessage(test.json)}); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if buf.String() != test.want {
+			t.Errorf("%s: Wanted:\n%s\nGot:\n%s", test.name, test.want, buf.String())
+		}
+	}
+}
@@ -1,5 +1,8 @@
 module github.com/joshturge/goclash
 
-go 1.13
+go 1.16
 
-require github.com/google/go-querystring v1.0.0
+require (
+	github.com/google/go-querystring v1.0.0
+	github.com/prometheus/client_golang v1.12.2
+)
@@ -0,0 +1,304 @@
+package activity
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+)
+
+// Signal is a change in a member that shows they were active
+type Signal string
+
+// Signals that mark a member as active
+const (
+	Donations   Signal = "donations"
+	Received    Signal = "donationsReceived"
+	Trophies    Signal = "trophies"
+	AttackWins  Signal = "attackWins"
+	ExpLevel    Signal = "expLevel"
+	Achievement Signal = "achievement"
+	WarAttack   Signal = "warAttack"
+	Joined      Signal = "joined"
+)
+
+// State is what is known about a member from their last snapshot
+type State struct {
+	Tag  string `json:"tag"`
+	Name string `json:"name"`
+	// LastSeen is the last time a change in the member was seen
+	LastSeen time.Time `json:"lastSeen"`
+	// Signal is the change the member was last seen by
+	Signal Signal `json:"signal"`
+
+	Donations    int            `json:"donations"`
+	Received     int            `json:"received"`
+	Trophies     int            `json:"trophies"`
+	AttackWins   int            `json:"attackWins"`
+	ExpLevel     int            `json:"expLevel"`
+	Achievements map[string]int `json:"achievements,omitempty"`
+	WarAttacks   int            `json:"warAttacks"`
+}
+
+// Inactive is the time since the member was last seen
+func (s *State) Inactive(now time.Time) time.Duration {
+	return now.Sub(s.LastSeen)
+}
+
+// Estimator keeps the state of every member of a clan and infers when each was
+// last active from the changes between snapshots. The state can be saved and
+// loaded so it survives restarts
+type Estimator struct {
+	mu sync.Mutex
+	// Members is keyed by member tag
+	Members map[string]*State `json:"members"`
+	// WarTag identifies the war the members' war attacks are counted for
+	WarTag string `json:"warTag"`
+}
+
+// NewEstimator will create an empty Estimator
+func NewEstimator() *Estimator {
+	return &Estimator{Members: make(map[string]*State)}
+}
+
+// Load will read an estimator saved with Save
+func Load(r io.Reader) (*Estimator, error) {
+	e := NewEstimator()
+	if err := json.NewDecoder(r).Decode(e); err != nil {
+		return nil, fmt.Errorf("could not decode activity: %s", err.Error())
+	}
+	return e, nil
+}
+
+// Save will write the estimator as json
+func (e *Estimator) Save(w io.Writer) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := json.NewEncoder(w).Encode(e); err != nil {
+		return fmt.Errorf("could not encode activity: %s", err.Error())
+	}
+	return nil
+}
+
+// state will get the state of a member, creating it if the member is new
+func (e *Estimator) state(tag, name string, now time.Time) (*State, bool) {
+	if e.Members == nil {
+		e.Members = make(map[string]*State)
+	}
+	s, ok := e.Members[tag]
+	if !ok {
+		s = &State{Tag: tag, LastSeen: now, Signal: Joined}
+		e.Members[tag] = s
+	}
+	if name != "" {
+		s.Name = name
+	}
+	return s, ok
+}
+
+func (s *State) seen(signal Signal, now time.Time) {
+	if !now.Before(s.LastSeen) {
+		s.LastSeen, s.Signal = now, signal
+	}
+}
+
+// AddMembers will record a snapshot of the clans member list. Members that left
+// the clan are forgotten
+func (e *Estimator) AddMembers(members []*goclash.Member, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	current := make(map[string]bool, len(members))
+	for _, member := range members {
+		current[member.Tag] = true
+		s, known := e.state(member.Tag, member.Name, now)
+		if known {
+			switch {
+			// counters only go down when they are reset with the season
+			case member.Donations > s.Donations:
+				s.seen(Donations, now)
+			case member.DonationsReceived > s.Received:
+				s.seen(Received, now)
+			// trophies are also lost on defence, so only gains count
+			case member.Trophies > s.Trophies && s.Trophies != 0:
+				s.seen(Trophies, now)
+			case member.ExpLevel > s.ExpLevel && s.ExpLevel != 0:
+				s.seen(ExpLevel, now)
+			}
+		}
+		s.Donations, s.Received = member.Donations, member.DonationsReceived
+		s.Trophies = member.Trophies
+		if member.ExpLevel > s.ExpLevel {
+			s.ExpLevel = member.ExpLevel
+		}
+	}
+
+	for tag := range e.Members {
+		if !current[tag] {
+			delete(e.Members, tag)
+		}
+	}
+}
+
+// AddPlayer will record a snapshot of a member's profile, which shows changes to
+// attack wins and achievements that the member list does not
+func (e *Estimator) AddPlayer(player *goclash.Player, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s, known := e.state(player.Tag, player.Name, now)
+	if known {
+		switch {
+		case player.AttackWins > s.AttackWins && s.AttackWins != 0:
+			s.seen(AttackWins, now)
+		case player.ExpLevel > s.ExpLevel && s.ExpLevel != 0:
+			s.seen(ExpLevel, now)
+		case achievementsChanged(s.Achievements, player.Achievements):
+			s.seen(Achievement, now)
+		}
+	}
+
+	s.AttackWins = player.AttackWins
+	if player.ExpLevel > s.ExpLevel {
+		s.ExpLevel = player.ExpLevel
+	}
+	s.Achievements = make(map[string]int, len(player.Achievements))
+	for _, a := range player.Achievements {
+		s.Achievements[a.Name] = a.Value
+	}
+}
+
+// achievementsChanged reports whether any achievement value grew. Nothing has
+// changed if there is no earlier snapshot of the achievements
+func achievementsChanged(prev map[string]int, achievements []goclash.Achievement) bool {
+	if len(prev) == 0 {
+		return false
+	}
+	for _, a := range achievements {
+		if value, ok := prev[a.Name]; ok && a.Value > value {
+			return true
+		}
+	}
+	return false
+}
+
+// AddWar will record the attacks members made in a war. Attacks are counted per
+// war so a new war does not mark anyone as active until they attack
+func (e *Estimator) AddWar(w *goclash.War, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	warTag := w.Clan.Tag + w.OpponentClan.Tag + w.PreparationStartTime.String()
+	newWar := warTag != e.WarTag
+	e.WarTag = warTag
+
+	for _, member := range w.Clan.Team {
+		s, ok := e.Members[member.Tag]
+		if !ok {
+			continue
+		}
+		if newWar {
+			s.WarAttacks = 0
+		}
+		if len(member.Attacks) > s.WarAttacks {
+			s.seen(WarAttack, now)
+		}
+		s.WarAttacks = len(member.Attacks)
+	}
+}
+
+// Inactive will list members that have not been seen for at least d, the longest
+// inactive first
+func (e *Estimator) Inactive(d time.Duration, now time.Time) []*State {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var inactive []*State
+	for _, s := range e.Members {
+		if s.Inactive(now) >= d {
+			copied := *s
+			inactive = append(inactive, &copied)
+		}
+	}
+	sort.SliceStable(inactive, func(i, j int) bool {
+		if inactive[i].LastSeen.Equal(inactive[j].LastSeen) {
+			return inactive[i].Tag < inactive[j].Tag
+		}
+		return inactive[i].LastSeen.Before(inactive[j].LastSeen)
+	})
+	return inactive
+}
+
+// Tracker polls a clan and feeds an Estimator
+type Tracker struct {
+	client  *goclash.Client
+	clanTag string
+
+	Estimator *Estimator
+	// Players also fetches every member's profile on each poll, which finds more
+	// activity at the cost of a request per member
+	Players bool
+	// War also fetches the current war on each poll
+	War bool
+	// WarError holds the error of the last current war fetch, which fails for
+	// clans with a private war log. A failed war fetch gives no war signal and
+	// does not fail the poll
+	WarError error
+}
+
+// NewTracker will create a new Tracker for a clan with an empty Estimator
+func NewTracker(client *goclash.Client, clanTag string) *Tracker {
+	return &Tracker{
+		client:    client,
+		clanTag:   clanTag,
+		Estimator: NewEstimator(),
+		Players:   true,
+		War:       true,
+	}
+}
+
+// Poll will fetch the clan and update the estimator. Members whose profile could
+// not be fetched are skipped and reported in the error
+func (t *Tracker) Poll() error {
+	now := time.Now().UTC()
+
+	members, err := t.client.Clan.GetMembers(t.clanTag, nil)
+	if err != nil {
+		return fmt.Errorf("could not get members: %s", err.Error())
+	}
+	t.Estimator.AddMembers(members, now)
+
+	var failed []string
+	if t.Players {
+		for _, member := range members {
+			player, err := t.client.Player.Get(member.Tag)
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("%s: %s", member.Tag, err.Error()))
+				continue
+			}
+			t.Estimator.AddPlayer(player, now)
+		}
+	}
+
+	if t.War {
+		currentWar, err := t.client.Clan.GetCurrentWar(t.clanTag)
+		if err != nil {
+			t.WarError = fmt.Errorf("could not get current war: %s", err.Error())
+		} else {
+			t.WarError = nil
+			if currentWar.State == "inWar" || currentWar.State == "warEnded" {
+				t.Estimator.AddWar(currentWar, now)
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("could not get members: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
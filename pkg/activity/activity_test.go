@@ -0,0 +1,106 @@
+package activity_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/activity"
+	"github.com/joshturge/goclash/pkg/clash"
+)
+
+func TestEstimator(t *testing.T) {
+	day := 24 * time.Hour
+	start := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	e := activity.NewEstimator()
+
+	e.AddMembers([]*goclash.Member{
+		{Tag: "#P2", Name: "donator", Donations: 10, Trophies: 3000},
+		{Tag: "#P8", Name: "attacker", Trophies: 3000},
+		{Tag: "#P9", Name: "idle", Trophies: 3000},
+		{Tag: "#PP", Name: "warrior", Trophies: 3000},
+	}, start)
+	e.AddPlayer(&goclash.Player{Tag: "#P8", AttackWins: 3, Achievements: []goclash.Achievement{
+		{Name: "Gold Grab", Value: 100}}}, start)
+
+	e.AddMembers([]*goclash.Member{
+		{Tag: "#P2", Name: "donator", Donations: 40, Trophies: 3000},
+		{Tag: "#P8", Name: "attacker", Trophies: 3000},
+		// losing trophies on defence is not activity
+		{Tag: "#P9", Name: "idle", Trophies: 2980},
+		{Tag: "#PP", Name: "warrior", Trophies: 3000},
+	}, start.Add(2*day))
+	e.AddPlayer(&goclash.Player{Tag: "#P8", AttackWins: 3, Achievements: []goclash.Achievement{
+		{Name: "Gold Grab", Value: 5000}}}, start.Add(3*day))
+	e.AddWar(&goclash.War{State: "inWar", Clan: goclash.WarClan{Tag: "#2PP", Team: []goclash.WarMember{
+		{Tag: "#PP", Attacks: []goclash.Attack{{Stars: 3}}}}}}, start.Add(4*day))
+
+	var buf bytes.Buffer
+	if err := e.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	e, err := activity.Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := start.Add(7 * day)
+	inactive := e.Inactive(5*day, now)
+	if len(inactive) != 2 || inactive[0].Tag != "#P9" || inactive[1].Tag != "#P2" {
+		t.Fatalf("unexpected inactive members: %+v", inactive)
+	}
+	if inactive[1].Signal != activity.Donations || inactive[1].Inactive(now) != 5*day {
+		t.Errorf("unexpected state: %+v", inactive[1])
+	}
+	if e.Members["#P8"].Signal != activity.Achievement || e.Members["#PP"].Signal != activity.WarAttack {
+		t.Errorf("unexpected signals: %+v %+v", e.Members["#P8"], e.Members["#PP"])
+	}
+
+	e.AddMembers([]*goclash.Member{{Tag: "#P2"}}, now)
+	if len(e.Members) != 1 {
+		t.Errorf("expected members that left to be forgotten: %+v", e.Members)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/clans/#2PP/members":
+			w.Write([]byte(`{"items": [{"tag": "#P2", "name": "two"}]}`))
+		case "/players/#P2":
+			w.Write([]byte(`{"tag": "#P2", "name": "two", "attackWins": 50}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"reason": "accessDenied"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := goclash.NewClient("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	client.SetLogger(log.New(ioutil.Discard, "", 0))
+
+	tracker := activity.NewTracker(client, "#2PP")
+	joined := time.Now().UTC().Add(-24 * time.Hour)
+	tracker.Estimator.AddMembers([]*goclash.Member{{Tag: "#P2", Name: "two"}}, joined)
+
+	if err := tracker.Poll(); err != nil {
+		t.Fatalf("expected a private war log to not fail the poll: %v", err)
+	}
+	if tracker.WarError == nil {
+		t.Error("expected the war error to be kept")
+	}
+
+	// the first profile snapshot only records the attack wins
+	if s := tracker.Estimator.Members["#P2"]; !s.LastSeen.Equal(joined) || s.AttackWins != 50 {
+		t.Errorf("unexpected state: %+v", s)
+	}
+}
@@ -0,0 +1,481 @@
+package army
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/gamedata"
+)
+
+// Kind is the part of the army camp or spell factory a unit is housed in
+type Kind int
+
+// Kinds of units that can be part of an army
+const (
+	Troop Kind = iota
+	SiegeMachine
+	Spell
+)
+
+// Section prefixes used in the army parameter of a CopyArmy link
+const (
+	unitSection  = 'u'
+	spellSection = 's'
+	heroSection  = 'h'
+)
+
+// Markers used in a hero entry, e.g. 0p1e0_1 is the Barbarian King with the
+// Electro Owl and the Barbarian Puppet and Rage Vial
+const (
+	petMarker       = 'p'
+	equipmentMarker = 'e'
+)
+
+// Unit is a single type of troop or spell in an army
+type Unit struct {
+	Id    int
+	Kind  Kind
+	Name  string
+	Count int
+	// Housing is the space a single unit takes up, 0 if the unit is not known
+	Housing int
+}
+
+// Known reports whether the unit id could be resolved to a name
+func (u *Unit) Known() bool {
+	return u.Name != ""
+}
+
+// Gear is a pet or piece of equipment given to a hero
+type Gear struct {
+	Id int
+	// Name is empty if the id is not known
+	Name string
+}
+
+// Hero is a hero in an army with the pet and equipment it was given
+type Hero struct {
+	Id   int
+	Name string
+	// Pet is nil if the hero was not given one
+	Pet       *Gear
+	Equipment []Gear
+}
+
+// Army is a composition of troops, siege machines, spells and heroes
+type Army struct {
+	Units  []Unit
+	Spells []Unit
+	Heroes []Hero
+}
+
+// Parse will parse an army from either a CopyArmy link or the value of its army
+// parameter, e.g. u10x0-2x3s1x9h0p1e0_1. Links are accepted with or without a
+// scheme
+func Parse(s string) (*Army, error) {
+	s = strings.TrimSpace(s)
+	if isLink(s) {
+		link, err := goclash.ParseLink(s)
+		if err != nil {
+			return nil, err
+		}
+		return FromLink(link)
+	}
+	return Decode(s)
+}
+
+// isLink reports whether s is a link rather than an army parameter, which never
+// holds a host, a query or a scheme
+func isLink(s string) bool {
+	return strings.Contains(s, "://") || strings.Contains(strings.ToLower(s), "clashofclans.com") ||
+		strings.Contains(s, "action=")
+}
+
+// FromLink will parse the army of a CopyArmy link
+func FromLink(link *goclash.Link) (*Army, error) {
+	if link.Action != goclash.CopyArmy {
+		return nil, fmt.Errorf("expected a %s link but got %s", goclash.CopyArmy, link.Action)
+	}
+	return Decode(link.Army)
+}
+
+// Decode will decode the army parameter of a CopyArmy link
+func Decode(s string) (*Army, error) {
+	var army Army
+	for len(s) > 0 {
+		section := s[0]
+		end := strings.IndexAny(s[1:], "ush")
+		var body string
+		if end < 0 {
+			body, s = s[1:], ""
+		} else {
+			body, s = s[1:end+1], s[end+1:]
+		}
+
+		if section == heroSection {
+			heroes, err := decodeHeroes(body)
+			if err != nil {
+				return nil, err
+			}
+			army.Heroes = append(army.Heroes, heroes...)
+			continue
+		}
+
+		units, err := decodeSection(body, section)
+		if err != nil {
+			return nil, err
+		}
+
+		switch section {
+		case unitSection:
+			army.Units = append(army.Units, units...)
+		case spellSection:
+			army.Spells = append(army.Spells, units...)
+		}
+	}
+
+	if len(army.Units) == 0 && len(army.Spells) == 0 && len(army.Heroes) == 0 {
+		return nil, fmt.Errorf("army is empty")
+	}
+
+	return &army, nil
+}
+
+func decodeSection(body string, section byte) ([]Unit, error) {
+	if section != unitSection && section != spellSection {
+		return nil, fmt.Errorf("unknown army section %q", section)
+	}
+	if body == "" {
+		return nil, fmt.Errorf("army section %q is empty", section)
+	}
+
+	entries := strings.Split(body, "-")
+	units := make([]Unit, 0, len(entries))
+	for _, entry := range entries {
+		parts := strings.Split(entry, "x")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("army entry %q is not in the form <count>x<id>", entry)
+		}
+		count, err := strconv.Atoi(parts[0])
+		if err != nil || count <= 0 {
+			return nil, fmt.Errorf("army entry %q has an invalid count", entry)
+		}
+		id, err := strconv.Atoi(parts[1])
+		if err != nil || id < 0 {
+			return nil, fmt.Errorf("army entry %q has an invalid id", entry)
+		}
+
+		if section == spellSection {
+			units = append(units, newSpell(id, count))
+		} else {
+			units = append(units, newUnit(id, count))
+		}
+	}
+
+	return units, nil
+}
+
+func decodeHeroes(body string) ([]Hero, error) {
+	if body == "" {
+		return nil, fmt.Errorf("army section %q is empty", heroSection)
+	}
+
+	entries := strings.Split(body, "-")
+	heroes := make([]Hero, 0, len(entries))
+	for _, entry := range entries {
+		rest := entry
+		equipment := ""
+		if i := strings.IndexByte(rest, equipmentMarker); i >= 0 {
+			rest, equipment = rest[:i], rest[i+1:]
+		}
+		pet := ""
+		hasPet := false
+		if i := strings.IndexByte(rest, petMarker); i >= 0 {
+			rest, pet, hasPet = rest[:i], rest[i+1:], true
+		}
+
+		id, err := strconv.Atoi(rest)
+		if err != nil || id < 0 {
+			return nil, fmt.Errorf("hero entry %q has an invalid id", entry)
+		}
+		hero := newHero(id)
+
+		if hasPet {
+			petId, err := strconv.Atoi(pet)
+			if err != nil || petId < 0 {
+				return nil, fmt.Errorf("hero entry %q has an invalid pet id", entry)
+			}
+			gear := newGear(petId, gamedata.Pet)
+			hero.Pet = &gear
+		}
+		if strings.IndexByte(entry, equipmentMarker) >= 0 {
+			for _, part := range strings.Split(equipment, "_") {
+				equipmentId, err := strconv.Atoi(part)
+				if err != nil || equipmentId < 0 {
+					return nil, fmt.Errorf("hero entry %q has an invalid equipment id", entry)
+				}
+				hero.Equipment = append(hero.Equipment, newGear(equipmentId, gamedata.Equipment))
+			}
+		}
+
+		heroes = append(heroes, hero)
+	}
+
+	return heroes, nil
+}
+
+func newHero(id int) Hero {
+	hero := Hero{Id: id}
+	if item, ok := gamedata.Default().ByCategoryLinkId(id, gamedata.Hero); ok {
+		hero.Name = item.Name
+	}
+	return hero
+}
+
+func newGear(id int, category gamedata.Category) Gear {
+	gear := Gear{Id: id}
+	if item, ok := gamedata.Default().ByCategoryLinkId(id, category); ok {
+		gear.Name = item.Name
+	}
+	return gear
+}
+
+func newUnit(id, count int) Unit {
+	unit := Unit{Id: id, Kind: Troop, Count: count}
+	if item, ok := gamedata.Default().ByLinkId(id, false); ok {
+		unit.Name, unit.Housing = item.Name, item.HousingSpace
+		if item.Category == gamedata.SiegeMachine {
+			unit.Kind = SiegeMachine
+		}
+	}
+	return unit
+}
+
+func newSpell(id, count int) Unit {
+	spell := Unit{Id: id, Kind: Spell, Count: count}
+	if item, ok := gamedata.Default().ByLinkId(id, true); ok {
+		spell.Name, spell.Housing = item.Name, item.HousingSpace
+	}
+	return spell
+}
+
+// Add will add count units with the given name to the army. The name must be one
+// of the names the API uses for troops, siege machines, spells and heroes. A hero
+// can only be added once and without a pet or equipment, use AddHero to give it
+// some
+func (a *Army) Add(name string, count int) error {
+	if count <= 0 {
+		return fmt.Errorf("count must be positive")
+	}
+	item, ok := gamedata.Default().Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown troop or spell %q", name)
+	}
+
+	switch item.Category {
+	case gamedata.Troop, gamedata.SiegeMachine:
+		a.Units = append(a.Units, newUnit(item.LinkId, count))
+	case gamedata.Spell:
+		a.Spells = append(a.Spells, newSpell(item.LinkId, count))
+	case gamedata.Hero:
+		if count != 1 {
+			return fmt.Errorf("only one %s can be part of an army", item.Name)
+		}
+		return a.AddHero(item.Name, "")
+	default:
+		return fmt.Errorf("%s can not be part of an army", item.Name)
+	}
+	return nil
+}
+
+// AddHero will add a hero to the army with a pet and equipment. The pet may be
+// empty if the hero is not given one
+func (a *Army) AddHero(name, pet string, equipment ...string) error {
+	item, ok := gamedata.Default().Lookup(name)
+	if !ok || item.Category != gamedata.Hero {
+		return fmt.Errorf("unknown hero %q", name)
+	}
+	for _, hero := range a.Heroes {
+		if hero.Id == item.LinkId {
+			return fmt.Errorf("%s is already part of the army", item.Name)
+		}
+	}
+
+	hero := newHero(item.LinkId)
+	if pet != "" {
+		petItem, ok := gamedata.Default().Lookup(pet)
+		if !ok || petItem.Category != gamedata.Pet {
+			return fmt.Errorf("unknown pet %q", pet)
+		}
+		gear := newGear(petItem.LinkId, gamedata.Pet)
+		hero.Pet = &gear
+	}
+	for _, name := range equipment {
+		equipmentItem, ok := gamedata.Default().Lookup(name)
+		if !ok || equipmentItem.Category != gamedata.Equipment {
+			return fmt.Errorf("unknown equipment %q", name)
+		}
+		if equipmentItem.Hero != item.Name {
+			return fmt.Errorf("%s can not be given to %s", equipmentItem.Name, item.Name)
+		}
+		hero.Equipment = append(hero.Equipment, newGear(equipmentItem.LinkId, gamedata.Equipment))
+	}
+
+	a.Heroes = append(a.Heroes, hero)
+	return nil
+}
+
+// Encode will encode the army into the army parameter of a CopyArmy link
+func (a *Army) Encode() string {
+	var b strings.Builder
+	encodeSection(&b, unitSection, a.Units)
+	encodeSection(&b, spellSection, a.Spells)
+	encodeHeroes(&b, a.Heroes)
+	return b.String()
+}
+
+func encodeHeroes(b *strings.Builder, heroes []Hero) {
+	if len(heroes) == 0 {
+		return
+	}
+	b.WriteByte(heroSection)
+	for i, hero := range heroes {
+		if i > 0 {
+			b.WriteByte('-')
+		}
+		b.WriteString(strconv.Itoa(hero.Id))
+		if hero.Pet != nil {
+			b.WriteByte(petMarker)
+			b.WriteString(strconv.Itoa(hero.Pet.Id))
+		}
+		for j, equipment := range hero.Equipment {
+			if j == 0 {
+				b.WriteByte(equipmentMarker)
+			} else {
+				b.WriteByte('_')
+			}
+			b.WriteString(strconv.Itoa(equipment.Id))
+		}
+	}
+}
+
+func encodeSection(b *strings.Builder, section byte, units []Unit) {
+	if len(units) == 0 {
+		return
+	}
+	b.WriteByte(section)
+	for i, unit := range units {
+		if i > 0 {
+			b.WriteByte('-')
+		}
+		b.WriteString(strconv.Itoa(unit.Count))
+		b.WriteByte('x')
+		b.WriteString(strconv.Itoa(unit.Id))
+	}
+}
+
+// Link will create a CopyArmy link for the army
+func (a *Army) Link() string {
+	return (&goclash.Link{Action: goclash.CopyArmy, Army: a.Encode()}).String()
+}
+
+// HousingSpace is the space the army takes up in the army camps, siege machine
+// workshop and spell factory
+type HousingSpace struct {
+	Troops        int
+	SiegeMachines int
+	Spells        int
+}
+
+// HousingSpace will add up the housing space of every known unit
+func (a *Army) HousingSpace() HousingSpace {
+	var space HousingSpace
+	for _, unit := range a.Units {
+		if unit.Kind == SiegeMachine {
+			space.SiegeMachines += unit.Housing * unit.Count
+			continue
+		}
+		space.Troops += unit.Housing * unit.Count
+	}
+	for _, spell := range a.Spells {
+		space.Spells += spell.Housing * spell.Count
+	}
+	return space
+}
+
+// Unknown will list the units whose id could not be resolved
+func (a *Army) Unknown() []Unit {
+	var unknown []Unit
+	for _, unit := range append(append([]Unit{}, a.Units...), a.Spells...) {
+		if !unit.Known() {
+			unknown = append(unknown, unit)
+		}
+	}
+	return unknown
+}
+
+// Locked will list the units of the army that the player has not unlocked in
+// their home village
+func (a *Army) Locked(player *goclash.Player) []Unit {
+	unlocked := make(map[string]bool, len(player.Troops)+len(player.Spells))
+	for _, troop := range append(append([]goclash.Troop{}, player.Troops...), player.Spells...) {
+		if troop.Village == "" || troop.Village == "home" {
+			unlocked[troop.Name] = troop.Level > 0
+		}
+	}
+
+	var locked []Unit
+	for _, unit := range append(append([]Unit{}, a.Units...), a.Spells...) {
+		if !unlocked[unit.Name] {
+			locked = append(locked, unit)
+		}
+	}
+	return locked
+}
+
+// Validate will check the army can be trained by the player and fits within the
+// given housing space
+func (a *Army) Validate(player *goclash.Player, capacity HousingSpace) error {
+	var problems []string
+	for _, unit := range a.Unknown() {
+		problems = append(problems, fmt.Sprintf("unknown unit id %d", unit.Id))
+	}
+	for _, unit := range a.Locked(player) {
+		if unit.Known() {
+			problems = append(problems, fmt.Sprintf("%s is not unlocked", unit.Name))
+		}
+	}
+
+	heroes := make(map[string]bool, len(player.Heros))
+	for _, hero := range player.Heros {
+		heroes[hero.Name] = hero.Level > 0
+	}
+	for _, hero := range a.Heroes {
+		if hero.Name == "" {
+			problems = append(problems, fmt.Sprintf("unknown hero id %d", hero.Id))
+		} else if !heroes[hero.Name] {
+			problems = append(problems, fmt.Sprintf("%s is not unlocked", hero.Name))
+		}
+	}
+
+	space := a.HousingSpace()
+	if space.Troops > capacity.Troops {
+		problems = append(problems, fmt.Sprintf("troops take up %d of %d housing space",
+			space.Troops, capacity.Troops))
+	}
+	if space.SiegeMachines > capacity.SiegeMachines {
+		problems = append(problems, fmt.Sprintf("siege machines take up %d of %d housing space",
+			space.SiegeMachines, capacity.SiegeMachines))
+	}
+	if space.Spells > capacity.Spells {
+		problems = append(problems, fmt.Sprintf("spells take up %d of %d housing space",
+			space.Spells, capacity.Spells))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("army is not valid: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package army_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joshturge/goclash/pkg/army"
+	"github.com/joshturge/goclash/pkg/clash"
+)
+
+func TestParse(t *testing.T) {
+	a, err := army.Parse("https://link.clashofclans.com/en?action=CopyArmy&army=u10x0-2x3-1x51s1x9-2x1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(a.Units) != 3 || len(a.Spells) != 2 {
+		t.Fatalf("unexpected army: %+v", a)
+	}
+	if a.Units[1].Name != "Giant" || a.Units[1].Count != 2 || a.Spells[0].Name != "Poison Spell" {
+		t.Errorf("units were not resolved: %+v", a)
+	}
+
+	want := army.HousingSpace{Troops: 20, SiegeMachines: 1, Spells: 5}
+	if got := a.HousingSpace(); got != want {
+		t.Errorf("Wanted: %+v\tGot: %+v", want, got)
+	}
+
+	if got := a.Encode(); got != "u10x0-2x3-1x51s1x9-2x1" {
+		t.Errorf("army did not round trip: %s", got)
+	}
+
+	// links pasted without a scheme
+	for _, link := range []string{"link.clashofclans.com/en?action=CopyArmy&army=u10x0-2x3-1x51s1x9-2x1",
+		"Link.ClashOfClans.com/?army=u10x0-2x3-1x51s1x9-2x1&action=CopyArmy"} {
+		a, err := army.Parse(link)
+		if err != nil {
+			t.Errorf("%s: %v", link, err)
+			continue
+		}
+		if got := a.Encode(); got != "u10x0-2x3-1x51s1x9-2x1" {
+			t.Errorf("%s: unexpected army %s", link, got)
+		}
+	}
+	if _, err := army.Parse("example.com/?action=CopyArmy&army=u10x0"); err == nil {
+		t.Error("expected a link to another site to be rejected")
+	}
+
+	for _, invalid := range []string{"", "u", "u10", "u0x0", "x10x0", "u10x0s"} {
+		if _, err := army.Decode(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestHeroes(t *testing.T) {
+	var a army.Army
+	if err := a.Add("Hog Rider", 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AddHero("Barbarian King", "Electro Owl", "Barbarian Puppet", "Rage Vial"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AddHero("Archer Queen", "", "Giant Arrow"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AddHero("Royal Champion", "", "Rage Vial"); err == nil {
+		t.Error("expected equipment of another hero to be rejected")
+	}
+
+	encoded := a.Encode()
+	if encoded != "u10x11h0p1e0_1-1e12" {
+		t.Fatalf("unexpected army: %s", encoded)
+	}
+
+	decoded, err := army.Parse("https://link.clashofclans.com/en?action=CopyArmy&army=" + encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Heroes) != 2 {
+		t.Fatalf("unexpected heroes: %+v", decoded.Heroes)
+	}
+	king := decoded.Heroes[0]
+	if king.Name != "Barbarian King" || king.Pet == nil || king.Pet.Name != "Electro Owl" ||
+		len(king.Equipment) != 2 || king.Equipment[1].Name != "Rage Vial" {
+		t.Errorf("unexpected hero: %+v", king)
+	}
+	if queen := decoded.Heroes[1]; queen.Name != "Archer Queen" || queen.Pet != nil || len(queen.Equipment) != 1 {
+		t.Errorf("unexpected hero: %+v", queen)
+	}
+	if got := decoded.Encode(); got != encoded {
+		t.Errorf("army did not round trip: %s", got)
+	}
+
+	for _, invalid := range []string{"h", "hp1", "h0p", "h0e", "h0e1_", "hx"} {
+		if _, err := army.Decode(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	var a army.Army
+	if err := a.Add("Hog Rider", 20); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Add("Healing Spell", 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Add("Grand Warden", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AddHero("Grand Warden", ""); err == nil {
+		t.Error("expected a hero to only be added once")
+	}
+
+	if !strings.Contains(a.Link(), "army=u20x11s3x1h2") {
+		t.Errorf("unexpected link: %s", a.Link())
+	}
+
+	player := &goclash.Player{
+		Troops: []goclash.Troop{{Name: "Hog Rider", Level: 5, Village: "home"}},
+		Spells: []goclash.Troop{{Name: "Healing Spell", Level: 3, Village: "home"}},
+		Heros:  []goclash.Troop{{Name: "Grand Warden", Level: 40, Village: "home"}},
+	}
+	if err := a.Validate(player, army.HousingSpace{Troops: 100, Spells: 6}); err != nil {
+		t.Error(err)
+	}
+
+	err := a.Validate(&goclash.Player{}, army.HousingSpace{Troops: 50, Spells: 6})
+	if err == nil || !strings.Contains(err.Error(), "Hog Rider is not unlocked") ||
+		!strings.Contains(err.Error(), "Grand Warden is not unlocked") ||
+		!strings.Contains(err.Error(), "100 of 50") {
+		t.Errorf("unexpected validation error: %v", err)
+	}
+}
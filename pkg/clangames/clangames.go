@@ -0,0 +1,295 @@
+package clangames
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+)
+
+// DefaultMemberCap is the most points a single member can earn in a Clan Games event
+const DefaultMemberCap = 4000
+
+// Tiers holds the clan total needed to unlock each reward tier
+var Tiers = []int{3000, 7500, 12000, 18000, 30000, 50000}
+
+// Tier is the reward tier reached with a clan total, 0 if no tier was reached
+func Tier(total int) int {
+	tier := 0
+	for i, points := range Tiers {
+		if total >= points {
+			tier = i + 1
+		}
+	}
+	return tier
+}
+
+// Schedule is when Clan Games run each month
+type Schedule struct {
+	// StartDay and EndDay are days of the month. The games end at the start of
+	// the hour on EndDay
+	StartDay int
+	EndDay   int
+	// Hour is the hour in UTC the games start and end
+	Hour int
+}
+
+// DefaultSchedule is the usual Clan Games schedule, the 22nd to the 28th at 08:00 UTC
+var DefaultSchedule = Schedule{StartDay: 22, EndDay: 28, Hour: 8}
+
+// Window will get the start and end of the games in the month of t
+func (s Schedule) Window(t time.Time) (start, end time.Time) {
+	t = t.UTC()
+	start = time.Date(t.Year(), t.Month(), s.StartDay, s.Hour, 0, 0, 0, time.UTC)
+	end = time.Date(t.Year(), t.Month(), s.EndDay, s.Hour, 0, 0, 0, time.UTC)
+	return start, end
+}
+
+// Active reports whether the games are running at t
+func (s Schedule) Active(t time.Time) bool {
+	start, end := s.Window(t)
+	return !t.Before(start) && t.Before(end)
+}
+
+// Next will get the next time after t the games start or end, and whether it is
+// the start
+func (s Schedule) Next(t time.Time) (time.Time, bool) {
+	start, end := s.Window(t)
+	switch {
+	case t.Before(start):
+		return start, true
+	case t.Before(end):
+		return end, false
+	}
+	start, _ = s.Window(time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC))
+	return start, true
+}
+
+// MemberSnapshot holds a members Games Champion value at the time of a snapshot
+type MemberSnapshot struct {
+	Tag   string `json:"tag"`
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+}
+
+// Snapshot holds the Games Champion values of every member of a clan
+type Snapshot struct {
+	ClanTag string            `json:"clanTag"`
+	Time    time.Time         `json:"time"`
+	Members []*MemberSnapshot `json:"members"`
+	// Missing lists members that were in the clan but could not be fetched
+	Missing []string `json:"missing,omitempty"`
+}
+
+// missing reports whether a member could not be fetched for the snapshot
+func (s *Snapshot) missing(tag string) bool {
+	for _, missing := range s.Missing {
+		if missing == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Save will write the snapshot as json so it can be compared after a restart
+func (s *Snapshot) Save(w io.Writer) error {
+	if err := json.NewEncoder(w).Encode(s); err != nil {
+		return fmt.Errorf("could not encode snapshot: %s", err.Error())
+	}
+	return nil
+}
+
+// LoadSnapshot will read a snapshot written by Save
+func LoadSnapshot(r io.Reader) (*Snapshot, error) {
+	var s Snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("could not decode snapshot: %s", err.Error())
+	}
+	return &s, nil
+}
+
+// Tracker takes snapshots of a clans members to work out their Clan Games points
+type Tracker struct {
+	client  *goclash.Client
+	clanTag string
+
+	// Schedule is used to decide when snapshots are due
+	Schedule Schedule
+	// MemberCap is passed to Compare when the games end
+	MemberCap int
+	// Start is the snapshot taken when the current games started, nil if the games
+	// are not running or have not been seen yet. It should be saved and restored
+	// so a restart during the games does not lose it
+	Start *Snapshot
+}
+
+// NewTracker will create a new Tracker for a clan using the DefaultSchedule
+func NewTracker(client *goclash.Client, clanTag string) *Tracker {
+	return &Tracker{
+		client:   client,
+		clanTag:  clanTag,
+		Schedule: DefaultSchedule,
+	}
+}
+
+// Snapshot will get every member of the clan and record their Games Champion
+// value. Members that can not be fetched are listed in Missing
+func (t *Tracker) Snapshot() (*Snapshot, error) {
+	clanTag, err := goclash.ParseTag(t.clanTag)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := t.client.Clan.GetMembers(clanTag.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not get members: %s", err.Error())
+	}
+
+	snapshot := Snapshot{
+		ClanTag: clanTag.String(),
+		Time:    time.Now().UTC(),
+		Members: make([]*MemberSnapshot, 0, len(members)),
+	}
+	for _, member := range members {
+		player, err := t.client.Player.Get(member.Tag)
+		if err != nil {
+			snapshot.Missing = append(snapshot.Missing, member.Tag)
+			continue
+		}
+
+		ms := MemberSnapshot{Tag: player.Tag, Name: player.Name}
+		if games, ok := player.Achievement(goclash.GamesChampion); ok {
+			ms.Value = games.Value
+		}
+		snapshot.Members = append(snapshot.Members, &ms)
+	}
+
+	return &snapshot, nil
+}
+
+// Poll will take the snapshots the schedule calls for at now. The start snapshot
+// is taken on the first poll while the games are running, so the tracker should be
+// polled at the times given by Schedule.Next. The end snapshot is taken on the
+// first poll after the games of the start snapshot ended, even if that is in a
+// later month, and the report of the games is returned, otherwise the report is nil
+func (t *Tracker) Poll(now time.Time) (*Report, error) {
+	if t.Start != nil {
+		if _, end := t.Schedule.Window(t.Start.Time); now.Before(end) {
+			return nil, nil
+		}
+		snapshot, err := t.Snapshot()
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Time = now.UTC()
+		report := Compare(t.Start, snapshot, t.MemberCap)
+		t.Start = nil
+		return report, nil
+	}
+
+	if t.Schedule.Active(now) {
+		snapshot, err := t.Snapshot()
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Time = now.UTC()
+		t.Start = snapshot
+	}
+	return nil, nil
+}
+
+// MemberPoints holds the points a member earned between two snapshots
+type MemberPoints struct {
+	Tag    string
+	Name   string
+	Points int
+	// Maxed is true if the member reached the member cap
+	Maxed bool
+}
+
+// Report holds the results of a Clan Games event
+type Report struct {
+	ClanTag string
+	Start   time.Time
+	End     time.Time
+	// Members is sorted by points, highest first
+	Members []*MemberPoints
+	Total   int
+	Tier    int
+	// Joined lists members that were not in the start snapshot so their points
+	// could not be worked out
+	Joined []string
+	// Left lists members that were not in the end snapshot
+	Left []string
+	// Missing lists members that could not be fetched for one of the snapshots so
+	// their points could not be worked out
+	Missing []string
+}
+
+// Compare will work out the points every member earned between two snapshots. A
+// memberCap of 0 uses the DefaultMemberCap
+func Compare(start, end *Snapshot, memberCap int) *Report {
+	if memberCap <= 0 {
+		memberCap = DefaultMemberCap
+	}
+
+	report := Report{
+		ClanTag: end.ClanTag,
+		Start:   start.Time,
+		End:     end.Time,
+	}
+
+	before := make(map[string]*MemberSnapshot, len(start.Members))
+	for _, member := range start.Members {
+		before[member.Tag] = member
+	}
+
+	for _, member := range end.Members {
+		prev, ok := before[member.Tag]
+		if !ok {
+			if start.missing(member.Tag) {
+				report.Missing = append(report.Missing, member.Tag)
+			} else {
+				report.Joined = append(report.Joined, member.Tag)
+			}
+			continue
+		}
+		delete(before, member.Tag)
+
+		points := member.Value - prev.Value
+		if points < 0 {
+			points = 0
+		}
+		if points > memberCap {
+			points = memberCap
+		}
+		report.Members = append(report.Members, &MemberPoints{
+			Tag:    member.Tag,
+			Name:   member.Name,
+			Points: points,
+			Maxed:  points == memberCap,
+		})
+		report.Total += points
+	}
+
+	for _, member := range start.Members {
+		if _, ok := before[member.Tag]; !ok {
+			continue
+		}
+		if end.missing(member.Tag) {
+			report.Missing = append(report.Missing, member.Tag)
+		} else {
+			report.Left = append(report.Left, member.Tag)
+		}
+	}
+
+	sort.SliceStable(report.Members, func(i, j int) bool {
+		return report.Members[i].Points > report.Members[j].Points
+	})
+	report.Tier = Tier(report.Total)
+
+	return &report
+}
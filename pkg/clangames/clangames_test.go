@@ -0,0 +1,164 @@
+package clangames_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clangames"
+	"github.com/joshturge/goclash/pkg/clash"
+)
+
+func newServer(games map[string]int, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		switch r.URL.Path {
+		case "/clans/#2PP/members":
+			w.Write([]byte(`{"items": [{"tag": "#P2", "name": "two"}, {"tag": "#P8", "name": "eight"},
+				{"tag": "#PY", "name": "broken"}]}`))
+		case "/players/#P2", "/players/#P8":
+			tag := r.URL.Path[len("/players/"):]
+			w.Write([]byte(`{"tag": "` + tag + `", "achievements": [{"name": "Games Champion", "value": ` +
+				strconv.Itoa(games[tag]) + `}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"reason": "notFound"}`))
+		}
+	}))
+}
+
+func newClient(t *testing.T, server *httptest.Server) *goclash.Client {
+	client, err := goclash.NewClient("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	client.SetLogger(log.New(ioutil.Discard, "", 0))
+	return client
+}
+
+func TestTracker(t *testing.T) {
+	games := map[string]int{"#P2": 10000, "#P8": 20000}
+	var requests int
+	server := newServer(games, &requests)
+	defer server.Close()
+	client := newClient(t, server)
+
+	tracker := clangames.NewTracker(client, "2pp")
+	start, err := tracker.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(start.Members) != 2 || start.Members[1].Value != 20000 {
+		t.Fatalf("unexpected snapshot: %+v", start.Members)
+	}
+	if len(start.Missing) != 1 || start.Missing[0] != "#PY" {
+		t.Errorf("expected the member that could not be fetched to be missing: %v", start.Missing)
+	}
+
+	var buf bytes.Buffer
+	if err := start.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	start, err = clangames.LoadSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	end := &clangames.Snapshot{ClanTag: "#2PP", Members: []*clangames.MemberSnapshot{
+		{Tag: "#P2", Name: "two", Value: 12500},
+		{Tag: "#P8", Name: "eight", Value: 29000},
+		{Tag: "#P9", Name: "new", Value: 3000},
+		{Tag: "#PY", Name: "broken", Value: 3000},
+	}}
+	report := clangames.Compare(start, end, 0)
+	if report.Total != 6500 || report.Tier != 1 {
+		t.Errorf("unexpected total: %d tier %d", report.Total, report.Tier)
+	}
+	if report.Members[0].Tag != "#P8" || !report.Members[0].Maxed || report.Members[1].Points != 2500 {
+		t.Errorf("unexpected members: %+v %+v", report.Members[0], report.Members[1])
+	}
+	if len(report.Joined) != 1 || report.Joined[0] != "#P9" || len(report.Left) != 0 {
+		t.Errorf("unexpected joined %v and left %v", report.Joined, report.Left)
+	}
+	if len(report.Missing) != 1 || report.Missing[0] != "#PY" {
+		t.Errorf("unexpected missing %v", report.Missing)
+	}
+}
+
+func TestPoll(t *testing.T) {
+	games := map[string]int{"#P2": 10000, "#P8": 20000}
+	var requests int
+	server := newServer(games, &requests)
+	defer server.Close()
+
+	tracker := clangames.NewTracker(newClient(t, server), "2pp")
+	poll := func(now time.Time) *clangames.Report {
+		report, err := tracker.Poll(now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+
+	if report := poll(time.Date(2020, 5, 21, 0, 0, 0, 0, time.UTC)); report != nil || requests != 0 {
+		t.Fatalf("expected nothing to happen before the games but got %+v after %d requests", report, requests)
+	}
+
+	start := time.Date(2020, 5, 22, 8, 0, 0, 0, time.UTC)
+	if report := poll(start); report != nil || tracker.Start == nil || !tracker.Start.Time.Equal(start) {
+		t.Fatalf("expected a start snapshot but got %+v", tracker.Start)
+	}
+	requests = 0
+	if poll(start.Add(24 * time.Hour)); requests != 0 || !tracker.Start.Time.Equal(start) {
+		t.Errorf("expected the start snapshot to only be taken once, %d requests", requests)
+	}
+
+	games["#P2"] += 1200
+	report := poll(time.Date(2020, 5, 28, 8, 0, 0, 0, time.UTC))
+	if report == nil || report.Total != 1200 || !report.Start.Equal(start) {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if tracker.Start != nil {
+		t.Error("expected the start snapshot to be cleared once the games ended")
+	}
+
+	requests = 0
+	if report := poll(time.Date(2020, 5, 29, 8, 0, 0, 0, time.UTC)); report != nil || requests != 0 {
+		t.Errorf("expected no report after the games but got %+v", report)
+	}
+
+	// the first poll after the games is in the next month
+	start = time.Date(2020, 6, 27, 20, 0, 0, 0, time.UTC)
+	poll(start)
+	games["#P8"] += 4000
+	report = poll(time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC))
+	if report == nil || report.Total != 4000 || !report.Start.Equal(start) {
+		t.Fatalf("expected the report of the June games but got %+v", report)
+	}
+	if tracker.Start != nil {
+		t.Error("expected the start snapshot to be cleared once the games ended")
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	schedule := clangames.DefaultSchedule
+	if !schedule.Active(time.Date(2020, 5, 25, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected the games to be active on the 25th")
+	}
+
+	next, start := schedule.Next(time.Date(2020, 12, 29, 0, 0, 0, 0, time.UTC))
+	if !start || !next.Equal(time.Date(2021, 1, 22, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected next event: %s start %t", next, start)
+	}
+
+	if tier := clangames.Tier(50000); tier != len(clangames.Tiers) {
+		t.Errorf("Wanted: %d\tGot: %d", len(clangames.Tiers), tier)
+	}
+}
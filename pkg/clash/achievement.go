@@ -0,0 +1,209 @@
+package goclash
+
+// AchievementName is the name the API uses to identify an achievement
+type AchievementName string
+
+// Achievements of the home village
+const (
+	BiggerCoffers        AchievementName = "Bigger Coffers"
+	GetThoseGoblins      AchievementName = "Get those Goblins!"
+	BiggerAndBetter      AchievementName = "Bigger & Better"
+	NiceAndTidy          AchievementName = "Nice and Tidy"
+	DiscoverNewTroops    AchievementName = "Discover New Troops"
+	GoldGrab             AchievementName = "Gold Grab"
+	ElixirEscapade       AchievementName = "Elixir Escapade"
+	SweetVictory         AchievementName = "Sweet Victory!"
+	EmpireBuilder        AchievementName = "Empire Builder"
+	WallBuster           AchievementName = "Wall Buster"
+	Humiliator           AchievementName = "Humiliator"
+	UnionBuster          AchievementName = "Union Buster"
+	Conqueror            AchievementName = "Conqueror"
+	Unbreakable          AchievementName = "Unbreakable"
+	FriendInNeed         AchievementName = "Friend in Need"
+	MortarMortar         AchievementName = "Mortar Mortar"
+	HeroicHeist          AchievementName = "Heroic Heist"
+	LeagueAllStar        AchievementName = "League All-Star"
+	XBowExterminator     AchievementName = "X-Bow Exterminator"
+	Firefighter          AchievementName = "Firefighter"
+	WarHero              AchievementName = "War Hero"
+	ClanWarWealth        AchievementName = "Clan War Wealth"
+	AntiArtillery        AchievementName = "Anti-Artillery"
+	SharingIsCaring      AchievementName = "Sharing is caring"
+	GamesChampion        AchievementName = "Games Champion"
+	WarLeagueLegend      AchievementName = "War League Legend"
+	WellSeasoned         AchievementName = "Well Seasoned"
+	SiegeSharer          AchievementName = "Siege Sharer"
+	AggressiveCapitalism AchievementName = "Aggressive Capitalism"
+	MostValuableClanmate AchievementName = "Most Valuable Clanmate"
+)
+
+// Achievements of the builder base
+const (
+	MasterEngineering   AchievementName = "Master Engineering"
+	NextGenerationModel AchievementName = "Next Generation Model"
+	UnBuildIt           AchievementName = "Un-Build It"
+	ChampionBuilder     AchievementName = "Champion Builder"
+	HighGear            AchievementName = "High Gear"
+	HiddenTreasures     AchievementName = "Hidden Treasures"
+)
+
+// achievementStars holds the value needed for each star of an achievement
+var achievementStars = map[AchievementName][3]int{
+	BiggerCoffers:        {2, 5, 10},
+	GetThoseGoblins:      {10, 50, 150},
+	BiggerAndBetter:      {8, 9, 10},
+	NiceAndTidy:          {5, 50, 500},
+	GoldGrab:             {20000, 1000000, 100000000},
+	ElixirEscapade:       {20000, 1000000, 100000000},
+	SweetVictory:         {75, 750, 1250},
+	EmpireBuilder:        {1, 2, 4},
+	WallBuster:           {10, 100, 2000},
+	Humiliator:           {10, 100, 2000},
+	UnionBuster:          {25, 250, 2500},
+	Conqueror:            {25, 500, 5000},
+	Unbreakable:          {10, 250, 5000},
+	FriendInNeed:         {100, 5000, 25000},
+	MortarMortar:         {25, 500, 5000},
+	HeroicHeist:          {20000, 250000, 1000000},
+	XBowExterminator:     {10, 250, 2500},
+	Firefighter:          {10, 250, 5000},
+	WarHero:              {10, 150, 1000},
+	ClanWarWealth:        {100000, 1000000, 100000000},
+	AntiArtillery:        {20, 100, 2000},
+	SharingIsCaring:      {100, 2000, 10000},
+	GamesChampion:        {10000, 100000, 1000000},
+	WarLeagueLegend:      {20, 250, 2500},
+	WellSeasoned:         {15000, 150000, 1500000},
+	SiegeSharer:          {50, 500, 5000},
+	AggressiveCapitalism: {10000, 100000, 1000000},
+	MostValuableClanmate: {40000, 2000000, 20000000},
+	MasterEngineering:    {2, 5, 9},
+	NextGenerationModel:  {1, 1, 1},
+	UnBuildIt:            {100, 1000, 2000},
+	ChampionBuilder:      {1000, 3000, 5000},
+	HighGear:             {1, 2, 3},
+	HiddenTreasures:      {3, 5, 10},
+}
+
+// SeasonAchievements are achievements whose value keeps counting after they are
+// completed. Comparing their value between snapshots of a player gives totals for
+// a period such as a season, e.g. troops donated for Friend in Need or clan games
+// points for Games Champion
+var SeasonAchievements = []AchievementName{
+	FriendInNeed,
+	SharingIsCaring,
+	SiegeSharer,
+	GamesChampion,
+	WarLeagueLegend,
+	AggressiveCapitalism,
+	MostValuableClanmate,
+	WellSeasoned,
+}
+
+// Achievement villages as reported by the API
+const (
+	HomeVillage        = "home"
+	BuilderBaseVillage = "builderBase"
+)
+
+// Id will get the typed identifier of the achievement
+func (a *Achievement) Id() AchievementName {
+	return AchievementName(a.Name)
+}
+
+// Completed reports whether every star of the achievement has been earned
+func (a *Achievement) Completed() bool {
+	return a.Stars >= 3
+}
+
+// Progress is the percentage of progress towards the next star of the achievement
+func (a *Achievement) Progress() float64 {
+	if a.Completed() || a.Target <= 0 {
+		return 100
+	}
+	return percent(a.Value, a.Target)
+}
+
+// Thresholds will get the value needed for each star of the achievement. It returns
+// false if the achievement is not known
+func (a *Achievement) Thresholds() ([3]int, bool) {
+	stars, ok := achievementStars[a.Id()]
+	return stars, ok
+}
+
+// TotalProgress is the percentage of progress towards all three stars of the
+// achievement. The target of the next star is used if the achievement is not known
+func (a *Achievement) TotalProgress() float64 {
+	if a.Completed() {
+		return 100
+	}
+	target := a.Target
+	if stars, ok := a.Thresholds(); ok {
+		target = stars[2]
+	}
+	if target <= 0 {
+		return 100
+	}
+	return percent(a.Value, target)
+}
+
+// SeasonScoped reports whether the achievement is one of the SeasonAchievements
+func (a *Achievement) SeasonScoped() bool {
+	for _, name := range SeasonAchievements {
+		if a.Id() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func percent(value, target int) float64 {
+	if value >= target {
+		return 100
+	}
+	return float64(value) / float64(target) * 100
+}
+
+// Achievement will find one of the players achievements
+func (p *Player) Achievement(name AchievementName) (*Achievement, bool) {
+	for i := range p.Achievements {
+		if p.Achievements[i].Id() == name {
+			return &p.Achievements[i], true
+		}
+	}
+	return nil, false
+}
+
+// AchievementsByVillage will group the players achievements by village
+func (p *Player) AchievementsByVillage() map[string][]Achievement {
+	villages := make(map[string][]Achievement)
+	for _, a := range p.Achievements {
+		village := a.Village
+		if village == "" {
+			village = HomeVillage
+		}
+		villages[village] = append(villages[village], a)
+	}
+	return villages
+}
+
+// AchievementGains will work out how much the value of each season scoped
+// achievement grew between two snapshots of a player
+func AchievementGains(previous, current *Player) map[AchievementName]int {
+	gains := make(map[AchievementName]int, len(SeasonAchievements))
+	for _, name := range SeasonAchievements {
+		cur, ok := current.Achievement(name)
+		if !ok {
+			continue
+		}
+		gain := cur.Value
+		if prev, ok := previous.Achievement(name); ok {
+			gain -= prev.Value
+		}
+		if gain < 0 {
+			gain = 0
+		}
+		gains[name] = gain
+	}
+	return gains
+}
@@ -30,6 +30,14 @@ type Clan struct {
 	MemberCount      int      `json:"members"`
 	Members          []Member `json:"memberList"`
 	Labels           []Label  `json:"labels"`
+	ChatLanguage     Language `json:"chatLanguage"`
+}
+
+// Language is the chat language of a clan
+type Language struct {
+	Id           int32  `json:"id"`
+	Name         string `json:"name"`
+	LanguageCode string `json:"languageCode"`
 }
 
 // BadgeUrl holds the url to badge images in various sizes
@@ -123,9 +131,12 @@ type War struct {
 	Clan                 WarClan   `json:"clan"`
 	OpponentClan         WarClan   `json:"opponent"`
 	TeamSize             int       `json:"teamSize"`
+	AttacksPerMember     int       `json:"attacksPerMember"`
 	StartTime            ClashTime `json:"startTime"`
 	PreparationStartTime ClashTime `json:"preparationStartTime"`
 	EndTime              ClashTime `json:"endTime"`
+	// Warning: WarStartTime is only set for clan war league wars
+	WarStartTime ClashTime `json:"warStartTime"`
 }
 
 // LeagueGroup is a clans current league group
@@ -168,6 +179,14 @@ func (c *ClanService) Search(query string, opt Optional) ([]*Clan, error) {
 		return nil, fmt.Errorf("search query is less than 3 characters long")
 	}
 
+	clans, _, err := c.SearchPage(query, opt)
+	return clans, err
+}
+
+// SearchPage will get a page of clans matching the name and options along with the
+// cursors needed to get the next page. The name can be empty as long as the
+// options hold at least one filter
+func (c *ClanService) SearchPage(name string, opt Optional) ([]*Clan, *Paging, error) {
 	var (
 		err error
 		v   = url.Values{}
@@ -177,35 +196,39 @@ func (c *ClanService) Search(query string, opt Optional) ([]*Clan, error) {
 	if !optionalNil(opt) {
 		v, err = opt.Encode()
 		if err != nil {
-			return nil, errInvalidOptional
+			return nil, nil, fmt.Errorf("%s: %s", errInvalidOptional.Error(), err.Error())
 		}
 	}
-	v.Add("name", url.QueryEscape(query))
+	if name != "" {
+		v.Set("name", name)
+	}
 
 	req, err = c.client.NewRequest("clans", v)
 	if err != nil {
-		return nil, fmt.Errorf("error creating new request: %s", err.Error())
+		return nil, nil, fmt.Errorf("error creating new request: %s", err.Error())
 	}
 
 	var items struct {
-		Clans []*Clan `json:"items"`
+		Clans  []*Clan `json:"items"`
+		Paging Paging  `json:"paging"`
 	}
 
 	_, err = c.client.Do(req, &items)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, nil, fmt.Errorf("could not do request: %w", err)
 	}
 
-	return items.Clans, nil
+	return items.Clans, &items.Paging, nil
 }
 
 // Get will retrieve a single clan by its clan tag.
 func (c *ClanService) Get(tag string) (*Clan, error) {
-	if err := validateTag(tag); err != nil {
+	clanTag, err := ParseTag(tag)
+	if err != nil {
 		return nil, err
 	}
 
-	req, err := c.client.NewRequest(buildURLPath("clans/", url.QueryEscape(tag)), nil)
+	req, err := c.client.NewRequest(buildURLPath("clans/", url.QueryEscape(clanTag.String())), nil)
 	if err != nil {
 		return nil, fmt.Errorf("could not create a new request: %s", err.Error())
 	}
@@ -214,7 +237,7 @@ func (c *ClanService) Get(tag string) (*Clan, error) {
 
 	_, err = c.client.Do(req, &clan)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, fmt.Errorf("could not do request: %w", err)
 	}
 
 	return &clan, nil
@@ -222,12 +245,12 @@ func (c *ClanService) Get(tag string) (*Clan, error) {
 
 // GetMembers will retrieve the members of a clan.
 func (c *ClanService) GetMembers(tag string, opt Optional) ([]*Member, error) {
-	if err := validateTag(tag); err != nil {
+	clanTag, err := ParseTag(tag)
+	if err != nil {
 		return nil, err
 	}
 
 	var (
-		err error
 		v   = url.Values{}
 		req *http.Request
 	)
@@ -239,7 +262,7 @@ func (c *ClanService) GetMembers(tag string, opt Optional) ([]*Member, error) {
 		}
 	}
 
-	req, err = c.client.NewRequest(buildURLPath("clans/", url.QueryEscape(tag), "/members"), v)
+	req, err = c.client.NewRequest(buildURLPath("clans/", url.QueryEscape(clanTag.String()), "/members"), v)
 	if err != nil {
 		return nil, fmt.Errorf("could not create a new request: %s", err.Error())
 	}
@@ -250,7 +273,7 @@ func (c *ClanService) GetMembers(tag string, opt Optional) ([]*Member, error) {
 
 	_, err = c.client.Do(req, &items)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, fmt.Errorf("could not do request: %w", err)
 	}
 
 	return items.Members, nil
@@ -258,12 +281,12 @@ func (c *ClanService) GetMembers(tag string, opt Optional) ([]*Member, error) {
 
 // GetWarLogs will retrieve a clans war logs if it's made public
 func (c *ClanService) GetWarLogs(tag string, opt Optional) ([]*WarLog, error) {
-	if err := validateTag(tag); err != nil {
+	clanTag, err := ParseTag(tag)
+	if err != nil {
 		return nil, err
 	}
 
 	var (
-		err error
 		v   = url.Values{}
 		req *http.Request
 	)
@@ -275,7 +298,7 @@ func (c *ClanService) GetWarLogs(tag string, opt Optional) ([]*WarLog, error) {
 		}
 	}
 
-	req, err = c.client.NewRequest(buildURLPath("clans/", url.QueryEscape(tag), "/warlog"), v)
+	req, err = c.client.NewRequest(buildURLPath("clans/", url.QueryEscape(clanTag.String()), "/warlog"), v)
 	if err != nil {
 		return nil, fmt.Errorf("could not create a new request: %s", err.Error())
 	}
@@ -286,7 +309,7 @@ func (c *ClanService) GetWarLogs(tag string, opt Optional) ([]*WarLog, error) {
 
 	_, err = c.client.Do(req, &items)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, fmt.Errorf("could not do request: %w", err)
 	}
 
 	return items.WarLogs, nil
@@ -294,11 +317,13 @@ func (c *ClanService) GetWarLogs(tag string, opt Optional) ([]*WarLog, error) {
 
 // GetCurrentWar will retrieve a clans current war if there is one
 func (c *ClanService) GetCurrentWar(tag string) (*War, error) {
-	if err := validateTag(tag); err != nil {
+	clanTag, err := ParseTag(tag)
+	if err != nil {
 		return nil, err
 	}
 
-	req, err := c.client.NewRequest(buildURLPath("clans/", url.QueryEscape(tag), "/currentwar"), nil)
+	req, err := c.client.NewRequest(buildURLPath("clans/", url.QueryEscape(clanTag.String()),
+		"/currentwar"), nil)
 	if err != nil {
 		return nil, fmt.Errorf("could not create a new request: %s", err.Error())
 	}
@@ -307,14 +332,21 @@ func (c *ClanService) GetCurrentWar(tag string) (*War, error) {
 
 	_, err = c.client.Do(req, &currentWar)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, fmt.Errorf("could not do request: %w", err)
 	}
 
 	return &currentWar, nil
 }
 
-func (c *ClanService) getLeagueGroup(pathStr string) (*LeagueGroup, error) {
-	req, err := c.client.NewRequest(pathStr, nil)
+// GetLeagueGroup will get a clans league group
+func (c *ClanService) GetLeagueGroup(tag string) (*LeagueGroup, error) {
+	clanTag, err := ParseTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.client.NewRequest(buildURLPath("clans/", url.QueryEscape(clanTag.String()),
+		"/currentwar/leaguegroup"), nil)
 	if err != nil {
 		return nil, fmt.Errorf("could not create a new request: %s", err.Error())
 	}
@@ -323,18 +355,32 @@ func (c *ClanService) getLeagueGroup(pathStr string) (*LeagueGroup, error) {
 
 	_, err = c.client.Do(req, &leagueGroup)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, fmt.Errorf("could not do request: %w", err)
 	}
 
 	return &leagueGroup, nil
 }
 
-// GetLeagueGroup will get a clans league group
-func (c *ClanService) GetLeagueGroup(tag string) (*LeagueGroup, error) {
-	return c.getLeagueGroup(buildURLPath("clans/", url.QueryEscape(tag), "/currentwar/leaguegroup"))
-}
+// GetWarLeagueWar will get a single war of a clan war league round by its war tag.
+//
+// Breaking: it used to return a *LeagueGroup, which the endpoint never returns
+func (c *ClanService) GetWarLeagueWar(warTag string) (*War, error) {
+	tag, err := ParseTag(warTag)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.client.NewRequest(buildURLPath("clanwarleagues/wars/", url.QueryEscape(tag.String())), nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create a new request: %s", err.Error())
+	}
+
+	var leagueWar War
+
+	_, err = c.client.Do(req, &leagueWar)
+	if err != nil {
+		return nil, fmt.Errorf("could not do request: %w", err)
+	}
 
-// GetWarLeagueWar will get a clans league war
-func (c *ClanService) GetWarLeagueWar(warTag string) (*LeagueGroup, error) {
-	return c.getLeagueGroup(buildURLPath("clanwarleagues/wars/", url.QueryEscape(warTag)))
+	return &leagueWar, nil
 }
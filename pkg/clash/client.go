@@ -60,6 +60,11 @@ func (c *Client) SetTimeout(duration time.Duration) {
 	c.httpclient.Timeout = duration
 }
 
+// SetLogger will replace the logger requests are logged to
+func (c *Client) SetLogger(logger *log.Logger) {
+	c.logger = logger
+}
+
 // NewRequest will create a new request to be sent to the Clash of Clans API
 func (c *Client) NewRequest(path string, urlVal url.Values) (*http.Request, error) {
 	var url strings.Builder
@@ -91,7 +96,7 @@ func (c *Client) NewRequest(path string, urlVal url.Values) (*http.Request, erro
 func (c *Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
 	resp, err := c.httpclient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, fmt.Errorf("could not do request: %w", err)
 	}
 
 	// TODO(joshturge): can't seem to get the content type of the response
@@ -108,12 +113,12 @@ func (c *Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
 	decoder := json.NewDecoder(&body)
 
 	if resp.StatusCode >= http.StatusBadRequest {
-		errResp := ErrorResponse{Response: resp}
+		errResp := ErrorResponse{Response: resp, StatusCode: resp.StatusCode}
 		if err = decoder.Decode(&errResp); err != nil {
 			return nil, fmt.Errorf("could not decode response into an error: %s", err.Error())
 		}
 
-		return nil, fmt.Errorf("a server error has occurred: %s", errResp.Error())
+		return nil, fmt.Errorf("a server error has occurred: %w", &errResp)
 	}
 
 	if err = decoder.Decode(v); err != nil {
@@ -123,13 +128,16 @@ func (c *Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
 	return resp, nil
 }
 
-// ErrorResponse is an error response from the Clash of Clans API
+// ErrorResponse is an error response from the Clash of Clans API. The services
+// wrap it, so it can be found with errors.As
 type ErrorResponse struct {
 	Response *http.Response
-	Reason   string `json:"reason"`
-	Message  string `json:"message"`
-	Type     string `json:"type"`
-	Details  string `json:"details"`
+	// StatusCode is the HTTP status code of the response
+	StatusCode int    `json:"-"`
+	Reason     string `json:"reason"`
+	Message    string `json:"message"`
+	Type       string `json:"type"`
+	Details    string `json:"details"`
 }
 
 // Error formats a string with information about an error
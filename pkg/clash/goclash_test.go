@@ -1,9 +1,17 @@
 package goclash_test
 
 import (
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/joshturge/goclash/pkg/clash"
 )
@@ -350,3 +358,381 @@ func TestLabelList(t *testing.T) {
 		fmt.Printf("Label ID: %d\nLabel Name: %s\n\n", label.Id, label.Name)
 	}
 }
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		in   string
+		want goclash.Tag
+	}{
+		{"#RQ8JLVQ", "#RQ8JLVQ"},
+		{" rq8jlvq ", "#RQ8JLVQ"},
+		{"#2pp o", "#2PP0"},
+		{"#0", "#0"},
+	}
+
+	for _, tt := range tests {
+		got, err := goclash.ParseTag(tt.in)
+		if err != nil {
+			t.Errorf("ParseTag(%q): %s", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Wanted: %s\tGot: %s", tt.want, got)
+		}
+	}
+
+	for _, invalid := range []string{"", "#", "   ", "#ABC", "#2PP!"} {
+		if _, err := goclash.ParseTag(invalid); err == nil {
+			t.Errorf("expected ParseTag(%q) to fail", invalid)
+		}
+	}
+}
+
+func TestTagId(t *testing.T) {
+	for _, tag := range []goclash.Tag{"#RQ8JLVQ", "#2PP", "#9", "#VVVVVVVVV"} {
+		high, low, err := tag.Id()
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if got := goclash.TagFromId(high, low); got != tag {
+			t.Errorf("Wanted: %s\tGot: %s (high %d, low %d)", tag, got, high, low)
+		}
+	}
+
+	if _, _, err := goclash.Tag("#RQ8JLVQX").Id(); err == nil {
+		t.Error("expected an invalid tag to have no id")
+	}
+	for _, tag := range []goclash.Tag{"#RQ8JLVQQQQQ", "#VVVVVVVVVVVVVVVVVVVV"} {
+		if _, _, err := tag.Id(); err == nil {
+			t.Errorf("expected %s to be too long to have an id", tag)
+		}
+	}
+}
+
+func TestParseLink(t *testing.T) {
+	link, err := goclash.ParseLink("https://link.clashofclans.com/en?action=OpenPlayerProfile&tag=rq8jlvq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Action != goclash.OpenPlayerProfile || link.Tag != "#RQ8JLVQ" || link.Language != "en" {
+		t.Errorf("unexpected link: %+v", link)
+	}
+
+	army, err := goclash.ParseLink("https://link.clashofclans.com/de?action=CopyArmy&army=u10x0-2x3s1x9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if army.Action != goclash.CopyArmy || army.Army != "u10x0-2x3s1x9" {
+		t.Errorf("unexpected army link: %+v", army)
+	}
+
+	for _, pasted := range []string{
+		"https://link.clashofclans.com/en?action=OpenPlayerProfile&tag=#RQ8JLVQ",
+		"https://link.clashofclans.com/en?tag=#RQ8JLVQ&action=OpenPlayerProfile",
+		"link.clashofclans.com/en?action=OpenPlayerProfile&tag=%23RQ8JLVQ",
+	} {
+		link, err := goclash.ParseLink(pasted)
+		if err != nil {
+			t.Errorf("could not parse %s: %s", pasted, err.Error())
+			continue
+		}
+		if link.Action != goclash.OpenPlayerProfile || link.Tag != "#RQ8JLVQ" {
+			t.Errorf("unexpected link for %s: %+v", pasted, link)
+		}
+	}
+
+	for _, invalid := range []string{
+		"https://example.com/en?action=OpenPlayerProfile&tag=%23RQ8JLVQ",
+		"https://link.clashofclans.com/en?action=OpenClanProfile",
+		"https://link.clashofclans.com/en?tag=%23RQ8JLVQ",
+	} {
+		if _, err := goclash.ParseLink(invalid); err == nil {
+			t.Errorf("expected %s to be rejected", invalid)
+		}
+	}
+}
+
+func TestProfileLink(t *testing.T) {
+	want := "https://link.clashofclans.com/en?action=OpenClanProfile&tag=%232PP"
+	if got := (&goclash.Clan{Tag: "#2PP"}).ProfileLink(); got != want {
+		t.Errorf("Wanted: %s\tGot: %s", want, got)
+	}
+
+	link, err := goclash.ParseLink(goclash.PlayerLink("#RQ8JLVQ"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Tag != "#RQ8JLVQ" {
+		t.Errorf("Wanted: #RQ8JLVQ\tGot: %s", link.Tag)
+	}
+}
+
+func TestAchievements(t *testing.T) {
+	previous := &goclash.Player{Achievements: []goclash.Achievement{
+		{Name: "Friend in Need", Value: 30000, Stars: 3, Target: 25000, Village: "home"},
+		{Name: "Games Champion", Value: 50000, Stars: 1, Target: 100000, Village: "home"},
+	}}
+	current := &goclash.Player{Achievements: []goclash.Achievement{
+		{Name: "Friend in Need", Value: 31500, Stars: 3, Target: 25000, Village: "home"},
+		{Name: "Games Champion", Value: 54000, Stars: 1, Target: 100000, Village: "home"},
+		{Name: "Master Engineering", Value: 5, Stars: 2, Target: 9, Village: "builderBase"},
+	}}
+
+	gains := goclash.AchievementGains(previous, current)
+	if gains[goclash.FriendInNeed] != 1500 || gains[goclash.GamesChampion] != 4000 {
+		t.Errorf("unexpected gains: %v", gains)
+	}
+
+	games, ok := current.Achievement(goclash.GamesChampion)
+	if !ok || !games.SeasonScoped() {
+		t.Fatalf("expected Games Champion to be season scoped: %+v", games)
+	}
+	if got := games.Progress(); got != 54 {
+		t.Errorf("Wanted: 54\tGot: %f", got)
+	}
+	if got := games.TotalProgress(); got != 5.4 {
+		t.Errorf("Wanted: 5.4\tGot: %f", got)
+	}
+
+	villages := current.AchievementsByVillage()
+	if len(villages[goclash.HomeVillage]) != 2 || len(villages[goclash.BuilderBaseVillage]) != 1 {
+		t.Errorf("unexpected villages: %v", villages)
+	}
+}
+
+func TestSeason(t *testing.T) {
+	// May 2020 ended on Monday the 25th
+	before := time.Date(2020, 5, 25, 4, 59, 0, 0, time.UTC)
+	if id := goclash.SeasonId(before); id != "2020-05" {
+		t.Errorf("Wanted: 2020-05\tGot: %s", id)
+	}
+	if id := goclash.SeasonId(before.Add(time.Minute)); id != "2020-06" {
+		t.Errorf("Wanted: 2020-06\tGot: %s", id)
+	}
+
+	end := goclash.SeasonEnd(time.Date(2020, 12, 30, 0, 0, 0, 0, time.UTC))
+	if want := time.Date(2021, 1, 25, 5, 0, 0, 0, time.UTC); !end.Equal(want) {
+		t.Errorf("Wanted: %s\tGot: %s", want, end)
+	}
+	if start := goclash.SeasonStart(end.Add(-time.Hour)); !start.Equal(time.Date(2020, 12, 28, 5, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected season start: %s", start)
+	}
+}
+
+func TestLocations(t *testing.T) {
+	locations := goclash.NewLocations(nil)
+
+	germany, err := locations.Resolve("de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if germany.Id != 32000094 || !germany.IsCountry {
+		t.Errorf("unexpected location: %+v", germany)
+	}
+	if location, err := locations.Resolve("International"); err != nil || location.Id != goclash.InternationalLocationId {
+		t.Errorf("unexpected location: %+v %v", location, err)
+	}
+	if location, ok := locations.ById(32000249); !ok || location.Code != "US" {
+		t.Errorf("unexpected location: %+v", location)
+	}
+	if _, err := locations.Resolve("Atlantis"); err == nil {
+		t.Error("expected an unknown location to be rejected")
+	}
+	// Australia is both a region and a country
+	if location, err := locations.Resolve("australia"); err != nil || location.Id != 32000021 || !location.IsCountry {
+		t.Errorf("expected the country to be preferred: %+v %v", location, err)
+	}
+
+	if regions := locations.Regions(); len(regions) != 7 {
+		t.Errorf("Wanted: 7 regions\tGot: %d", len(regions))
+	}
+	if len(locations.Countries())+len(locations.Regions()) != len(locations.All()) {
+		t.Error("every location should be a country or a region")
+	}
+}
+
+func TestLocationsRetry(t *testing.T) {
+	fail := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"reason": "inMaintenance"}`))
+			return
+		}
+		w.Write([]byte(`{"items": [{"id": 32000001, "name": "Atlantis", "isCountry": true, "countryCode": "AT"},
+			{"id": 32000002, "name": "Atlantis", "isCountry": true, "countryCode": "AX"}]}`))
+	}))
+	defer server.Close()
+
+	c, err := goclash.NewClient("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL, _ = url.Parse(server.URL + "/")
+	c.SetLogger(log.New(ioutil.Discard, "", 0))
+
+	locations := goclash.NewLocations(c)
+	locations.SetRetryInterval(0)
+	if _, ok := locations.ByName("Atlantis"); ok {
+		t.Fatal("expected the snapshot to be used while the API can not be reached")
+	}
+
+	fail = false
+	if len(locations.All()) != 2 {
+		t.Errorf("expected the location list to be fetched again: %+v", locations.All())
+	}
+	if _, err := locations.Resolve("Atlantis"); err == nil || !strings.Contains(err.Error(), "32000001, 32000002") {
+		t.Errorf("expected an ambiguous name to be rejected: %v", err)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	label, err := goclash.ParseClanLabel("clan wars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if label != goclash.ClanWarsLabel || goclash.CompetitiveLabel.String() != "Competitive" {
+		t.Errorf("unexpected label: %d", label)
+	}
+
+	opts := goclash.ClanSearchOptions{Labels: []int32{56000010}, LabelNames: []string{"Clan Wars", "competitive"}}
+	v, err := opts.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.Get("labels"); got != "56000010,56000000,56000014" {
+		t.Errorf("Wanted: 56000010,56000000,56000014\tGot: %s", got)
+	}
+	if len(opts.Labels) != 1 {
+		t.Error("encoding should not change the options")
+	}
+
+	opts.LabelNames = []string{"Pirates"}
+	if _, err := opts.Encode(); err == nil {
+		t.Error("expected an unknown label to be rejected")
+	}
+
+	labels := goclash.NewLabels(nil)
+	if l, ok := labels.PlayerLabel("Active Donator"); !ok || l.Id != int32(goclash.PlayerActiveDonatorLabel) {
+		t.Errorf("unexpected player label: %+v", l)
+	}
+	if name, ok := labels.Name(56000015); !ok || name != "Newbie Friendly" {
+		t.Errorf("Wanted: Newbie Friendly\tGot: %s", name)
+	}
+}
+
+func TestClanSearchBuilder(t *testing.T) {
+	pages := map[string]string{
+		"": `{"items": [{"tag": "#2PP", "isWarLogPublic": true, "chatLanguage": {"languageCode": "DE"}},
+			{"tag": "#8QU", "isWarLogPublic": false}], "paging": {"cursors": {"after": "next"}}}`,
+		"next": `{"items": [{"tag": "#9LY", "isWarLogPublic": true, "chatLanguage": {"languageCode": "de"}}],
+			"paging": {"cursors": {}}}`,
+	}
+	var queries []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+		w.Write([]byte(pages[r.URL.Query().Get("after")]))
+	}))
+	defer server.Close()
+
+	c, err := goclash.NewClient("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL, _ = url.Parse(server.URL + "/")
+	c.SetLogger(log.New(ioutil.Discard, "", 0))
+
+	waits := 0
+	clans, err := c.Clan.NewSearch().
+		Throttle(func() { waits++ }).
+		Location("Germany").
+		Labels("Clan Wars").
+		WarFrequency(goclash.WarFrequencyAlways).
+		Members(10, 50).
+		PublicWarLog().
+		ChatLanguage("de").
+		WithLocations(goclash.NewLocations(nil)).
+		Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(clans) != 2 || clans[0].Tag != "#2PP" || clans[1].Tag != "#9LY" {
+		t.Errorf("unexpected clans: %+v", clans)
+	}
+	if len(queries) != 2 || queries[0].Get("locationId") != "32000094" || queries[0].Get("labels") != "56000000" ||
+		queries[0].Get("name") != "" {
+		t.Errorf("unexpected queries: %v", queries)
+	}
+	if waits != len(queries) {
+		t.Errorf("expected every page to be throttled: %d waits for %d pages", waits, len(queries))
+	}
+
+	for _, invalid := range []*goclash.ClanSearch{
+		c.Clan.NewSearch(),
+		c.Clan.NewSearch().Name("ab"),
+		c.Clan.NewSearch().Members(30, 20),
+		c.Clan.NewSearch().WarFrequency("sometimes"),
+	} {
+		if _, err := invalid.Options(); err == nil {
+			t.Errorf("expected the search to be rejected: %+v", invalid)
+		}
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"reason": "accessDenied", "message": "Invalid authorization"}`))
+	}))
+	defer server.Close()
+
+	c, err := goclash.NewClient("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL, _ = url.Parse(server.URL + "/")
+	c.SetLogger(log.New(ioutil.Discard, "", 0))
+
+	_, err = c.Clan.GetCurrentWar("#2PP")
+	var errResp *goclash.ErrorResponse
+	if !errors.As(err, &errResp) {
+		t.Fatalf("expected an error response but got %v", err)
+	}
+	if errResp.StatusCode != http.StatusForbidden || errResp.Reason != "accessDenied" {
+		t.Errorf("unexpected error response: %+v", errResp)
+	}
+	if errors.As(fmt.Errorf("could not do request: timeout"), &errResp) {
+		t.Error("expected other errors not to be an error response")
+	}
+}
+
+func TestPlayerHeroes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"tag": "#RQ8JLVQ", "townHallLevel": 16,
+			"heroes": [{"name": "Barbarian King", "level": 95, "maxLevel": 100, "village": "home"},
+				{"name": "Battle Machine", "level": 30, "maxLevel": 35, "village": "builderBase"}],
+			"heroEquipment": [{"name": "Giant Gauntlet", "level": 18, "maxLevel": 27, "village": "home"}]}`))
+	}))
+	defer server.Close()
+
+	c, err := goclash.NewClient("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.BaseURL, _ = url.Parse(server.URL + "/")
+	c.SetLogger(log.New(ioutil.Discard, "", 0))
+
+	player, err := c.Player.Get("#RQ8JLVQ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(player.Heros) != 2 || player.Heros[0].Name != "Barbarian King" || player.Heros[0].Level != 95 {
+		t.Errorf("unexpected heroes: %+v", player.Heros)
+	}
+	if len(player.HeroEquipment) != 1 || player.HeroEquipment[0].Name != "Giant Gauntlet" ||
+		player.HeroEquipment[0].MaxLevel != 27 {
+		t.Errorf("unexpected hero equipment: %+v", player.HeroEquipment)
+	}
+}
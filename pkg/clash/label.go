@@ -3,7 +3,9 @@ package goclash
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Label holds information about a Clash of Clans label
@@ -50,7 +52,7 @@ func (l *LabelService) labelList(lType string, opt *Control) ([]*Label, error) {
 
 	_, err = l.client.Do(req, &items)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, fmt.Errorf("could not do request: %w", err)
 	}
 
 	return items.Labels, nil
@@ -65,3 +67,252 @@ func (l *LabelService) ClanList(opt *Control) ([]*Label, error) {
 func (l *LabelService) PlayerList(opt *Control) ([]*Label, error) {
 	return l.labelList("players", opt)
 }
+
+// ClanLabel is the id of a label a clan can choose
+type ClanLabel int32
+
+// Labels clans can choose
+const (
+	ClanWarsLabel ClanLabel = 56000000 + iota
+	ClanWarLeagueLabel
+	TrophyPushingLabel
+	FriendlyWarsLabel
+	ClanGamesLabel
+	BuilderBaseLabel
+	BaseDesigningLabel
+	InternationalLabel
+	FarmingLabel
+	DonationsLabel
+	FriendlyLabel
+	TalkativeLabel
+	UnderdogLabel
+	RelaxedLabel
+	CompetitiveLabel
+	NewbieFriendlyLabel
+	ClanCapitalLabel
+)
+
+var clanLabelNames = map[ClanLabel]string{
+	ClanWarsLabel:       "Clan Wars",
+	ClanWarLeagueLabel:  "Clan War League",
+	TrophyPushingLabel:  "Trophy Pushing",
+	FriendlyWarsLabel:   "Friendly Wars",
+	ClanGamesLabel:      "Clan Games",
+	BuilderBaseLabel:    "Builder Base",
+	BaseDesigningLabel:  "Base Designing",
+	InternationalLabel:  "International",
+	FarmingLabel:        "Farming",
+	DonationsLabel:      "Donations",
+	FriendlyLabel:       "Friendly",
+	TalkativeLabel:      "Talkative",
+	UnderdogLabel:       "Underdog",
+	RelaxedLabel:        "Relaxed",
+	CompetitiveLabel:    "Competitive",
+	NewbieFriendlyLabel: "Newbie Friendly",
+	ClanCapitalLabel:    "Clan Capital",
+}
+
+// String is the English name of the label
+func (cl ClanLabel) String() string {
+	if name, ok := clanLabelNames[cl]; ok {
+		return name
+	}
+	return strconv.FormatInt(int64(cl), 10)
+}
+
+// PlayerLabel is the id of a label a player can choose
+type PlayerLabel int32
+
+// Labels players can choose
+const (
+	PlayerClanWarsLabel PlayerLabel = 57000000 + iota
+	PlayerClanWarLeagueLabel
+	PlayerTrophyPushingLabel
+	PlayerFriendlyWarsLabel
+	PlayerClanGamesLabel
+	PlayerBuilderBaseLabel
+	PlayerBaseDesigningLabel
+	PlayerFarmingLabel
+	PlayerActiveDonatorLabel
+	PlayerActiveDailyLabel
+	PlayerHungryLearnerLabel
+	PlayerFriendlyLabel
+	PlayerTalkativeLabel
+	PlayerTeacherLabel
+	PlayerCompetitiveLabel
+	PlayerVeteranLabel
+	PlayerNewbieLabel
+	PlayerAmateurAttackerLabel
+	PlayerClanCapitalLabel
+)
+
+var playerLabelNames = map[PlayerLabel]string{
+	PlayerClanWarsLabel:        "Clan Wars",
+	PlayerClanWarLeagueLabel:   "Clan War League",
+	PlayerTrophyPushingLabel:   "Trophy Pushing",
+	PlayerFriendlyWarsLabel:    "Friendly Wars",
+	PlayerClanGamesLabel:       "Clan Games",
+	PlayerBuilderBaseLabel:     "Builder Base",
+	PlayerBaseDesigningLabel:   "Base Designing",
+	PlayerFarmingLabel:         "Farming",
+	PlayerActiveDonatorLabel:   "Active Donator",
+	PlayerActiveDailyLabel:     "Active Daily",
+	PlayerHungryLearnerLabel:   "Hungry Learner",
+	PlayerFriendlyLabel:        "Friendly",
+	PlayerTalkativeLabel:       "Talkative",
+	PlayerTeacherLabel:         "Teacher",
+	PlayerCompetitiveLabel:     "Competitive",
+	PlayerVeteranLabel:         "Veteran",
+	PlayerNewbieLabel:          "Newbie",
+	PlayerAmateurAttackerLabel: "Amateur Attacker",
+	PlayerClanCapitalLabel:     "Clan Capital",
+}
+
+// String is the English name of the label
+func (pl PlayerLabel) String() string {
+	if name, ok := playerLabelNames[pl]; ok {
+		return name
+	}
+	return strconv.FormatInt(int64(pl), 10)
+}
+
+// ParseClanLabel will find a clan label by its English name, ignoring case and
+// spaces
+func ParseClanLabel(name string) (ClanLabel, error) {
+	for label, labelName := range clanLabelNames {
+		if labelKey(labelName) == labelKey(name) {
+			return label, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown clan label %q", name)
+}
+
+// ParsePlayerLabel will find a player label by its English name, ignoring case and
+// spaces
+func ParsePlayerLabel(name string) (PlayerLabel, error) {
+	for label, labelName := range playerLabelNames {
+		if labelKey(labelName) == labelKey(name) {
+			return label, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown player label %q", name)
+}
+
+// labelKey will normalise a label name so "clan-wars" matches "Clan Wars"
+func labelKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '_' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
+
+// Labels is a registry of clan and player labels. The labels are fetched from the
+// API the first time they are needed and cached from then on. If the API can not
+// be reached the labels known to this package are used instead until a later fetch
+// succeeds
+type Labels struct {
+	service *LabelService
+
+	fetch   fetchState
+	clans   []*Label
+	players []*Label
+}
+
+// NewLabels will create a new label registry. A nil client will only use the
+// labels known to this package
+func NewLabels(client *Client) *Labels {
+	var service *LabelService
+	if client != nil {
+		service = client.Label
+	}
+	return &Labels{service: service, fetch: fetchState{interval: DefaultRetryInterval}}
+}
+
+// SetRetryInterval will set how long to wait before fetching the labels again
+// after a failed fetch
+func (l *Labels) SetRetryInterval(interval time.Duration) {
+	l.fetch.mu.Lock()
+	defer l.fetch.mu.Unlock()
+	l.fetch.interval = interval
+}
+
+// load will fetch the labels the first time it is called, and again after
+// the retry interval while the API could not be reached
+func (l *Labels) load() {
+	l.fetch.mu.Lock()
+	defer l.fetch.mu.Unlock()
+
+	now := time.Now()
+	if l.service != nil && l.fetch.due(now) {
+		clans, err := l.service.ClanList(nil)
+		var players []*Label
+		if err == nil {
+			players, err = l.service.PlayerList(nil)
+		}
+		if err != nil {
+			l.service.client.logger.Printf("could not list labels, using known labels: %s", err.Error())
+		}
+		l.fetch.done(err == nil && len(clans) > 0 && len(players) > 0, now)
+		if l.fetch.fetched {
+			l.clans, l.players = clans, players
+			return
+		}
+	}
+	if l.clans != nil {
+		return
+	}
+
+	for id := ClanWarsLabel; id <= ClanCapitalLabel; id++ {
+		l.clans = append(l.clans, &Label{Id: int32(id), Name: id.String()})
+	}
+	for id := PlayerClanWarsLabel; id <= PlayerClanCapitalLabel; id++ {
+		l.players = append(l.players, &Label{Id: int32(id), Name: id.String()})
+	}
+}
+
+// Clans will list every clan label
+func (l *Labels) Clans() []*Label {
+	l.load()
+	l.fetch.mu.Lock()
+	defer l.fetch.mu.Unlock()
+	return l.clans
+}
+
+// Players will list every player label
+func (l *Labels) Players() []*Label {
+	l.load()
+	l.fetch.mu.Lock()
+	defer l.fetch.mu.Unlock()
+	return l.players
+}
+
+// ClanLabel will find a clan label by its name, ignoring case and spaces
+func (l *Labels) ClanLabel(name string) (*Label, bool) {
+	return findLabel(l.Clans(), name)
+}
+
+// PlayerLabel will find a player label by its name, ignoring case and spaces
+func (l *Labels) PlayerLabel(name string) (*Label, bool) {
+	return findLabel(l.Players(), name)
+}
+
+// Name will find the name of a clan or player label by its id
+func (l *Labels) Name(id int32) (string, bool) {
+	for _, label := range append(append([]*Label{}, l.Clans()...), l.Players()...) {
+		if label.Id == id {
+			return label.Name, true
+		}
+	}
+	return "", false
+}
+
+func findLabel(labels []*Label, name string) (*Label, bool) {
+	for _, label := range labels {
+		if labelKey(label.Name) == labelKey(name) {
+			return label, true
+		}
+	}
+	return nil, false
+}
@@ -75,7 +75,7 @@ func (l *LeagueService) List(opt *Control) ([]*League, error) {
 
 	_, err = l.client.Do(req, &items)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, fmt.Errorf("could not do request: %w", err)
 	}
 
 	return items.Leagues, nil
@@ -95,7 +95,7 @@ func (l *LeagueService) Get(leagueId int32) (*League, error) {
 
 	_, err = l.client.Do(req, &league)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, fmt.Errorf("could not do request: %w", err)
 	}
 
 	return &league, nil
@@ -130,7 +130,7 @@ func (l *LeagueService) GetSeasons(leagueId int32, opt *Control) ([]*LegendSeaso
 
 	_, err = l.client.Do(req, &items)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, fmt.Errorf("could not do request: %w", err)
 	}
 
 	return items.LegendSeasons, nil
@@ -139,15 +139,23 @@ func (l *LeagueService) GetSeasons(leagueId int32, opt *Control) ([]*LegendSeaso
 // GetSeasonRankings will get the rankings for a legend season
 func (l *LeagueService) GetSeasonRankings(leagueId int32, seasonId string,
 	opt *Control) ([]*LegendSeasonPlayer, error) {
+	players, _, err := l.GetSeasonRankingsPage(leagueId, seasonId, opt)
+	return players, err
+}
+
+// GetSeasonRankingsPage will get a page of the rankings for a legend season along
+// with the cursors needed to get the next page
+func (l *LeagueService) GetSeasonRankingsPage(leagueId int32, seasonId string,
+	opt *Control) ([]*LegendSeasonPlayer, *Paging, error) {
 	if opt != nil {
 		if opt.Before != "" && opt.After != "" {
-			return nil, fmt.Errorf("both Before and After have been set, this is not allowed")
+			return nil, nil, fmt.Errorf("both Before and After have been set, this is not allowed")
 		}
 	}
 
 	v, err := encodeOptional(opt)
 	if err != nil {
-		return nil, fmt.Errorf("could not encode optional arguments for request: %s", err.Error())
+		return nil, nil, fmt.Errorf("could not encode optional arguments for request: %s", err.Error())
 	}
 
 	var path strings.Builder
@@ -158,17 +166,18 @@ func (l *LeagueService) GetSeasonRankings(leagueId int32, seasonId string,
 
 	req, err := l.client.NewRequest(path.String(), v)
 	if err != nil {
-		return nil, fmt.Errorf("error creating new request: %s", err.Error())
+		return nil, nil, fmt.Errorf("error creating new request: %s", err.Error())
 	}
 
 	var items struct {
 		SeasonPlayers []*LegendSeasonPlayer `json:"items"`
+		Paging        Paging                `json:"paging"`
 	}
 
 	_, err = l.client.Do(req, &items)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, nil, fmt.Errorf("could not do request: %w", err)
 	}
 
-	return items.SeasonPlayers, nil
+	return items.SeasonPlayers, &items.Paging, nil
 }
@@ -0,0 +1,163 @@
+package goclash
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// LinkAction is the action an in-game link performs when it is opened
+type LinkAction string
+
+// Link actions supported by the game
+const (
+	OpenPlayerProfile LinkAction = "OpenPlayerProfile"
+	OpenClanProfile   LinkAction = "OpenClanProfile"
+	CopyArmy          LinkAction = "CopyArmy"
+)
+
+const (
+	linkHost = "link.clashofclans.com"
+	// DefaultLinkLanguage is the language used when generating links
+	DefaultLinkLanguage = "en"
+)
+
+var errNotGameLink = errors.New("link is not a Clash of Clans link")
+
+// Link is a parsed in-game share link
+type Link struct {
+	Action   LinkAction
+	Language string
+	// Tag is set for profile links
+	Tag Tag
+	// Army is the encoded army of a CopyArmy link
+	Army string
+	// Params holds every query parameter of the link
+	Params url.Values
+}
+
+// ParseLink will parse a share link such as
+// https://link.clashofclans.com/en?action=OpenPlayerProfile&tag=%23RQ8JLVQ. Links
+// pasted without a scheme or with an unescaped # in the tag are accepted
+func ParseLink(s string) (*Link, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse link: %s", err.Error())
+	}
+	if !strings.EqualFold(u.Host, linkHost) {
+		return nil, errNotGameLink
+	}
+	if u.Fragment != "" && strings.HasSuffix(u.RawQuery, "=") {
+		// an unescaped # starts the fragment, so the rest of the query is in it
+		u.RawQuery += "%23" + u.EscapedFragment()
+		u.Fragment = ""
+	}
+
+	params := u.Query()
+	link := Link{
+		Action:   LinkAction(params.Get("action")),
+		Language: strings.Trim(u.Path, "/"),
+		Army:     params.Get("army"),
+		Params:   params,
+	}
+	if link.Action == "" {
+		return nil, fmt.Errorf("link has no action")
+	}
+
+	switch link.Action {
+	case OpenPlayerProfile, OpenClanProfile:
+		link.Tag, err = ParseTag(params.Get("tag"))
+		if err != nil {
+			return nil, fmt.Errorf("link has an invalid tag: %s", err.Error())
+		}
+	case CopyArmy:
+		if link.Army == "" {
+			return nil, fmt.Errorf("army link has no army")
+		}
+	}
+
+	return &link, nil
+}
+
+// String will format the link so it can be shared
+func (l *Link) String() string {
+	params := url.Values{}
+	for key, values := range l.Params {
+		params[key] = values
+	}
+	params.Set("action", string(l.Action))
+	if l.Tag != "" {
+		params.Set("tag", l.Tag.String())
+	}
+	if l.Army != "" {
+		params.Set("army", l.Army)
+	}
+
+	language := l.Language
+	if language == "" {
+		language = DefaultLinkLanguage
+	}
+
+	u := url.URL{
+		Scheme:   "https",
+		Host:     linkHost,
+		Path:     "/" + language,
+		RawQuery: params.Encode(),
+	}
+	return u.String()
+}
+
+// PlayerLink will create a link that opens a players profile in game
+func PlayerLink(tag Tag) string {
+	return (&Link{Action: OpenPlayerProfile, Tag: tag}).String()
+}
+
+// ClanLink will create a link that opens a clans profile in game
+func ClanLink(tag Tag) string {
+	return (&Link{Action: OpenClanProfile, Tag: tag}).String()
+}
+
+// ProfileLink will create a link that opens the players profile in game
+func (p *Player) ProfileLink() string {
+	return PlayerLink(Tag(p.Tag))
+}
+
+// ProfileLink will create a link that opens the clans profile in game
+func (c *Clan) ProfileLink() string {
+	return ClanLink(Tag(c.Tag))
+}
+
+// linkTag will get the tag from a profile link with the expected action
+func linkTag(s string, action LinkAction) (string, error) {
+	link, err := ParseLink(s)
+	if err != nil {
+		return "", err
+	}
+	if link.Action != action {
+		return "", fmt.Errorf("expected a %s link but got %s", action, link.Action)
+	}
+	return link.Tag.String(), nil
+}
+
+// GetFromLink will get a player from a shared player profile link
+func (c *PlayerService) GetFromLink(link string) (*Player, error) {
+	tag, err := linkTag(link, OpenPlayerProfile)
+	if err != nil {
+		return nil, err
+	}
+	return c.Get(tag)
+}
+
+// GetFromLink will get a clan from a shared clan profile link
+func (c *ClanService) GetFromLink(link string) (*Clan, error) {
+	tag, err := linkTag(link, OpenClanProfile)
+	if err != nil {
+		return nil, err
+	}
+	return c.Get(tag)
+}
@@ -96,7 +96,7 @@ func (l *LocationService) List(opt *Control) ([]*Location, error) {
 
 	_, err = l.client.Do(req, &items)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, fmt.Errorf("could not do request: %w", err)
 	}
 
 	return items.Locations, nil
@@ -117,7 +117,7 @@ func (l *LocationService) Get(locationId int32) (*Location, error) {
 
 	_, err = l.client.Do(req, &location)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, fmt.Errorf("could not do request: %w", err)
 	}
 
 	return &location, nil
@@ -153,7 +153,7 @@ func (l *LocationService) GetClanRankings(locationId int32, opt *Control) ([]*Cl
 
 	_, err = l.client.Do(req, &items)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, fmt.Errorf("could not do request: %w", err)
 	}
 
 	return items.ClanRankings, nil
@@ -189,7 +189,7 @@ func (l *LocationService) GetPlayerRankings(locationId int32, opt *Control) ([]*
 
 	_, err = l.client.Do(req, &items)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, fmt.Errorf("could not do request: %w", err)
 	}
 
 	return items.PlayerRankings, nil
@@ -226,7 +226,7 @@ func (l *LocationService) GetClanVersusRankings(locationId int32, opt *Control)
 
 	_, err = l.client.Do(req, &items)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, fmt.Errorf("could not do request: %w", err)
 	}
 
 	return items.ClanVersusRankings, nil
@@ -263,7 +263,7 @@ func (l *LocationService) GetPlayerVersusRankings(locationId int32, opt *Control
 
 	_, err = l.client.Do(req, &items)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, fmt.Errorf("could not do request: %w", err)
 	}
 
 	return items.PlayerVersusRankings, nil
@@ -41,6 +41,9 @@ type ClanSearchOptions struct {
 	MinClanPoints int     `url:"minClanPoints,omitempty"`
 	MinClanLevel  int     `url:"minClanLevel,omitempty"`
 	Labels        []int32 `url:"labels,comma,omitempty"`
+	// LabelNames are clan label names such as "Clan Wars" that are added to Labels
+	// when the options are encoded
+	LabelNames []string `url:"-"`
 	Control
 }
 
@@ -50,7 +53,20 @@ func (cso *ClanSearchOptions) Encode() (url.Values, error) {
 	if cso.Before != "" && cso.After != "" {
 		return nil, errBeforeAfterSet
 	}
-	return encodeOptional(cso)
+	if len(cso.LabelNames) == 0 {
+		return encodeOptional(cso)
+	}
+
+	opts := *cso
+	opts.Labels = append([]int32{}, cso.Labels...)
+	for _, name := range cso.LabelNames {
+		label, err := ParseClanLabel(name)
+		if err != nil {
+			return nil, err
+		}
+		opts.Labels = append(opts.Labels, int32(label))
+	}
+	return encodeOptional(&opts)
 }
 
 // Control are optional parameters to control how much data you get back from the
@@ -58,7 +74,7 @@ func (cso *ClanSearchOptions) Encode() (url.Values, error) {
 type Control struct {
 	Limit  int    `url:"limit,omitempty"`
 	Before string `url:"before,omitempty"`
-	After  string `url:"afer,omitempty"`
+	After  string `url:"after,omitempty"`
 }
 
 // Encode will encode clan search options into url values so they can be passed
@@ -69,3 +85,12 @@ func (ctrl *Control) Encode() (url.Values, error) {
 	}
 	return encodeOptional(ctrl)
 }
+
+// Paging holds the cursors returned with a page of results. A cursor is empty if
+// there are no more results in that direction
+type Paging struct {
+	Cursors struct {
+		Before string `json:"before"`
+		After  string `json:"after"`
+	} `json:"cursors"`
+}
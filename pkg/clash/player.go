@@ -29,7 +29,8 @@ type Player struct {
 	VersusBattleWinCount int              `json:"versusBattleWinCount"`
 	LegendStatistics     LegendStatistics `json:"legendStatistics"`
 	Troops               []Troop          `json:"troops"`
-	Heros                []Troop          `json:"heros"`
+	Heros                []Troop          `json:"heroes"`
+	HeroEquipment        []Troop          `json:"heroEquipment"`
 	Spells               []Troop          `json:"spells"`
 	Labels               []Label          `json:"labels"`
 	Achievements         []Achievement    `json:"achievements"`
@@ -84,14 +85,14 @@ type PlayerService service
 
 // Get will get a player via a player tag
 func (c *PlayerService) Get(tag string) (*Player, error) {
-	if tag[:1] != "#" {
-		return nil, fmt.Errorf("player tag was not valid: tag length: %d, first character: %s",
-			len(tag), tag[:1])
+	playerTag, err := ParseTag(tag)
+	if err != nil {
+		return nil, err
 	}
 
 	var path strings.Builder
 	path.WriteString("players/")
-	path.WriteString(url.QueryEscape(tag))
+	path.WriteString(url.QueryEscape(playerTag.String()))
 
 	req, err := c.client.NewRequest(path.String(), nil)
 	if err != nil {
@@ -102,7 +103,7 @@ func (c *PlayerService) Get(tag string) (*Player, error) {
 
 	_, err = c.client.Do(req, &player)
 	if err != nil {
-		return nil, fmt.Errorf("could not do request: %s", err.Error())
+		return nil, fmt.Errorf("could not do request: %w", err)
 	}
 
 	return &player, nil
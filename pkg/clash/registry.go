@@ -0,0 +1,254 @@
+package goclash
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InternationalLocationId is the id of the International location which holds the
+// global rankings
+const InternationalLocationId = 32000006
+
+// DefaultRetryInterval is how long a registry waits before fetching from the API
+// again after a failed fetch
+const DefaultRetryInterval = time.Minute
+
+// fetchState decides when a registry should fetch from the API
+type fetchState struct {
+	mu      sync.Mutex
+	fetched bool
+	retry   time.Time
+	// interval is the time to wait after a failed fetch
+	interval time.Duration
+}
+
+// due reports whether the registry should fetch from the API. It must be called
+// with mu held
+func (fs *fetchState) due(now time.Time) bool {
+	return !fs.fetched && !now.Before(fs.retry)
+}
+
+// done records the result of a fetch. It must be called with mu held
+func (fs *fetchState) done(ok bool, now time.Time) {
+	fs.fetched = ok
+	if !ok {
+		fs.retry = now.Add(fs.interval)
+	}
+}
+
+// locationsSnapshot is a snapshot of the location list used when the API can not
+// be reached. Location ids rarely change so it is only refreshed occasionally
+//
+//go:embed locations.json
+var locationsSnapshot []byte
+
+// Locations is a registry of every location. The location list is fetched from
+// the API the first time it is needed and cached from then on. If the API can not
+// be reached the bundled snapshot is used instead until a later fetch succeeds
+type Locations struct {
+	service *LocationService
+
+	fetch     fetchState
+	locations []*Location
+	byId      map[int32]*Location
+	byCode    map[string]*Location
+	// byName can hold several locations as some regions and countries share a
+	// name, e.g. Australia
+	byName map[string][]*Location
+}
+
+// NewLocations will create a new location registry. A nil client will only use
+// the bundled snapshot
+func NewLocations(client *Client) *Locations {
+	var service *LocationService
+	if client != nil {
+		service = client.Location
+	}
+	return &Locations{service: service, fetch: fetchState{interval: DefaultRetryInterval}}
+}
+
+// SetRetryInterval will set how long to wait before fetching the location list
+// again after a failed fetch
+func (l *Locations) SetRetryInterval(interval time.Duration) {
+	l.fetch.mu.Lock()
+	defer l.fetch.mu.Unlock()
+	l.fetch.interval = interval
+}
+
+// load will fetch the location list the first time it is called, and again after
+// the retry interval while the API could not be reached
+func (l *Locations) load() {
+	l.fetch.mu.Lock()
+	defer l.fetch.mu.Unlock()
+
+	now := time.Now()
+	if l.service == nil || !l.fetch.due(now) {
+		if l.locations == nil {
+			l.index(snapshotLocations())
+		}
+		return
+	}
+
+	locations, err := l.service.List(nil)
+	if err != nil {
+		l.service.client.logger.Printf("could not list locations, using snapshot: %s", err.Error())
+	}
+	l.fetch.done(err == nil && len(locations) > 0, now)
+	if !l.fetch.fetched {
+		if l.locations == nil {
+			l.index(snapshotLocations())
+		}
+		return
+	}
+	l.index(locations)
+}
+
+// snapshotLocations will decode the bundled location snapshot
+func snapshotLocations() []*Location {
+	var snapshot struct {
+		Locations []*Location `json:"items"`
+	}
+	if err := json.Unmarshal(locationsSnapshot, &snapshot); err != nil {
+		panic("goclash: location snapshot is invalid: " + err.Error())
+	}
+	return snapshot.Locations
+}
+
+// index will replace the locations of the registry
+func (l *Locations) index(locations []*Location) {
+	l.locations = locations
+	l.byId = make(map[int32]*Location, len(locations))
+	l.byCode = make(map[string]*Location, len(locations))
+	l.byName = make(map[string][]*Location, len(locations)*2)
+	for _, location := range locations {
+		l.byId[location.Id] = location
+		if location.Code != "" {
+			l.byCode[strings.ToUpper(location.Code)] = location
+		}
+		l.addName(location.Name, location)
+		if location.LocalizedName != "" && !strings.EqualFold(location.LocalizedName, location.Name) {
+			l.addName(location.LocalizedName, location)
+		}
+	}
+}
+
+func (l *Locations) addName(name string, location *Location) {
+	key := strings.ToLower(name)
+	l.byName[key] = append(l.byName[key], location)
+}
+
+// All will list every location
+func (l *Locations) All() []*Location {
+	l.load()
+	l.fetch.mu.Lock()
+	defer l.fetch.mu.Unlock()
+	return l.locations
+}
+
+// Countries will list every location that is a country
+func (l *Locations) Countries() []*Location {
+	return l.filter(true)
+}
+
+// Regions will list every location that is not a country, such as Europe or
+// International
+func (l *Locations) Regions() []*Location {
+	return l.filter(false)
+}
+
+func (l *Locations) filter(country bool) []*Location {
+	var locations []*Location
+	for _, location := range l.All() {
+		if location.IsCountry == country {
+			locations = append(locations, location)
+		}
+	}
+	return locations
+}
+
+// ById will find a location by its id
+func (l *Locations) ById(id int32) (*Location, bool) {
+	l.load()
+	l.fetch.mu.Lock()
+	defer l.fetch.mu.Unlock()
+	location, ok := l.byId[id]
+	return location, ok
+}
+
+// ByCode will find a country by its ISO 3166 alpha-2 code such as DE
+func (l *Locations) ByCode(code string) (*Location, bool) {
+	l.load()
+	l.fetch.mu.Lock()
+	defer l.fetch.mu.Unlock()
+	location, ok := l.byCode[strings.ToUpper(strings.TrimSpace(code))]
+	return location, ok
+}
+
+// ByName will find a location by its English or localized name, ignoring case. If
+// a country and a region share the name the country is returned. Names shared by
+// several countries or several regions are not found, use Resolve to get an error
+// listing them
+func (l *Locations) ByName(name string) (*Location, bool) {
+	location, err := l.byNameMatch(name)
+	return location, err == nil && location != nil
+}
+
+// byNameMatch will find the location with a name, returning an error if the name
+// is ambiguous and nil if there is no location with the name
+func (l *Locations) byNameMatch(name string) (*Location, error) {
+	l.load()
+	l.fetch.mu.Lock()
+	defer l.fetch.mu.Unlock()
+
+	matches := l.byName[strings.ToLower(strings.TrimSpace(name))]
+	if len(matches) <= 1 {
+		if len(matches) == 0 {
+			return nil, nil
+		}
+		return matches[0], nil
+	}
+
+	var countries []*Location
+	for _, location := range matches {
+		if location.IsCountry {
+			countries = append(countries, location)
+		}
+	}
+	if len(countries) == 1 {
+		return countries[0], nil
+	}
+	if len(countries) > 1 {
+		matches = countries
+	}
+
+	ids := make([]string, len(matches))
+	for i, location := range matches {
+		ids[i] = strconv.FormatInt(int64(location.Id), 10)
+	}
+	return nil, fmt.Errorf("location %q is ambiguous, use one of the ids %s", name, strings.Join(ids, ", "))
+}
+
+// Resolve will find a location from an id, country code or name
+func (l *Locations) Resolve(s string) (*Location, error) {
+	if id, err := strconv.ParseInt(strings.TrimSpace(s), 10, 32); err == nil {
+		if location, ok := l.ById(int32(id)); ok {
+			return location, nil
+		}
+	}
+	if location, ok := l.ByCode(s); ok {
+		return location, nil
+	}
+	location, err := l.byNameMatch(s)
+	if err != nil {
+		return nil, err
+	}
+	if location != nil {
+		return location, nil
+	}
+	return nil, fmt.Errorf("unknown location %q", s)
+}
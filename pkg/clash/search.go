@@ -0,0 +1,270 @@
+package goclash
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WarFrequency is how often a clan says it goes to war
+type WarFrequency string
+
+// War frequencies a clan can choose
+const (
+	WarFrequencyAlways              WarFrequency = "always"
+	WarFrequencyMoreThanOncePerWeek WarFrequency = "moreThanOncePerWeek"
+	WarFrequencyOncePerWeek         WarFrequency = "oncePerWeek"
+	WarFrequencyLessThanOncePerWeek WarFrequency = "lessThanOncePerWeek"
+	WarFrequencyNever               WarFrequency = "never"
+	WarFrequencyUnknown             WarFrequency = "unknown"
+)
+
+// Valid reports whether the war frequency is one the API accepts
+func (wf WarFrequency) Valid() bool {
+	switch wf {
+	case WarFrequencyAlways, WarFrequencyMoreThanOncePerWeek, WarFrequencyOncePerWeek,
+		WarFrequencyLessThanOncePerWeek, WarFrequencyNever, WarFrequencyUnknown:
+		return true
+	}
+	return false
+}
+
+const (
+	// maxClanMembers is the most members a clan can have
+	maxClanMembers = 50
+	// DefaultSearchPages is the most pages a ClanSearch will request unless told
+	// otherwise
+	DefaultSearchPages = 10
+)
+
+// ClanSearch builds and runs a clan search. The API filters are checked before the
+// search is run and filters the API does not support are applied to every page of
+// results
+type ClanSearch struct {
+	service *ClanService
+
+	name      string
+	opts      ClanSearchOptions
+	location  string
+	labels    []string
+	filters   []func(*Clan) bool
+	limit     int
+	pageSize  int
+	maxPages  int
+	locations *Locations
+	throttle  func()
+}
+
+// NewSearch will start building a clan search
+func (c *ClanService) NewSearch() *ClanSearch {
+	return &ClanSearch{service: c, maxPages: DefaultSearchPages}
+}
+
+// Name will only match clans whose name contains name. It must be at least 3
+// characters long
+func (cs *ClanSearch) Name(name string) *ClanSearch {
+	cs.name = name
+	return cs
+}
+
+// WarFrequency will only match clans with the war frequency
+func (cs *ClanSearch) WarFrequency(wf WarFrequency) *ClanSearch {
+	cs.opts.WarFrequency = string(wf)
+	return cs
+}
+
+// Location will only match clans in a location given as an id, country code or
+// name
+func (cs *ClanSearch) Location(location string) *ClanSearch {
+	cs.location = location
+	return cs
+}
+
+// LocationId will only match clans in the location with the id
+func (cs *ClanSearch) LocationId(id int32) *ClanSearch {
+	cs.opts.LocationId = id
+	return cs
+}
+
+// Members will only match clans with between min and max members. A max of 0 is
+// not checked
+func (cs *ClanSearch) Members(min, max int) *ClanSearch {
+	cs.opts.MinMembers, cs.opts.MaxMembers = min, max
+	return cs
+}
+
+// MinClanPoints will only match clans with at least points clan points
+func (cs *ClanSearch) MinClanPoints(points int) *ClanSearch {
+	cs.opts.MinClanPoints = points
+	return cs
+}
+
+// MinClanLevel will only match clans of at least level
+func (cs *ClanSearch) MinClanLevel(level int) *ClanSearch {
+	cs.opts.MinClanLevel = level
+	return cs
+}
+
+// Labels will only match clans with every label, given by name
+func (cs *ClanSearch) Labels(names ...string) *ClanSearch {
+	cs.labels = append(cs.labels, names...)
+	return cs
+}
+
+// PublicWarLog will only match clans with a public war log
+func (cs *ClanSearch) PublicWarLog() *ClanSearch {
+	return cs.Filter(func(c *Clan) bool { return c.IsWarLogPublic })
+}
+
+// MaxRequiredTrophies will only match clans that require at most trophies to join
+func (cs *ClanSearch) MaxRequiredTrophies(trophies int) *ClanSearch {
+	return cs.Filter(func(c *Clan) bool { return c.RequiredTrophies <= trophies })
+}
+
+// ChatLanguage will only match clans with a chat language code such as EN
+func (cs *ClanSearch) ChatLanguage(code string) *ClanSearch {
+	return cs.Filter(func(c *Clan) bool { return strings.EqualFold(c.ChatLanguage.LanguageCode, code) })
+}
+
+// Filter will only keep clans fn returns true for. Filters are applied to the
+// results after they are returned by the API
+func (cs *ClanSearch) Filter(fn func(*Clan) bool) *ClanSearch {
+	cs.filters = append(cs.filters, fn)
+	return cs
+}
+
+// Limit will stop the search once n clans have matched. A limit of 0 returns every
+// clan found within the page limit
+func (cs *ClanSearch) Limit(n int) *ClanSearch {
+	cs.limit = n
+	return cs
+}
+
+// PageSize sets how many clans are requested at a time
+func (cs *ClanSearch) PageSize(n int) *ClanSearch {
+	cs.pageSize = n
+	return cs
+}
+
+// MaxPages sets the most pages that will be requested
+func (cs *ClanSearch) MaxPages(n int) *ClanSearch {
+	cs.maxPages = n
+	return cs
+}
+
+// WithLocations will use a location registry to look up locations instead of
+// creating a new one
+func (cs *ClanSearch) WithLocations(locations *Locations) *ClanSearch {
+	cs.locations = locations
+	return cs
+}
+
+// Throttle will call wait before every page is requested, so the requests of a
+// search can be spread out like any others
+func (cs *ClanSearch) Throttle(wait func()) *ClanSearch {
+	cs.throttle = wait
+	return cs
+}
+
+// Options will validate the search and build the options sent to the API
+func (cs *ClanSearch) Options() (*ClanSearchOptions, error) {
+	var problems []string
+	opts := cs.opts
+	opts.Limit = cs.pageSize
+
+	if cs.name != "" && len(cs.name) < 3 {
+		problems = append(problems, "name must be at least 3 characters long")
+	}
+	if opts.WarFrequency != "" && !WarFrequency(opts.WarFrequency).Valid() {
+		problems = append(problems, fmt.Sprintf("unknown war frequency %q", opts.WarFrequency))
+	}
+	if opts.MinMembers < 0 || opts.MinMembers > maxClanMembers || opts.MaxMembers < 0 ||
+		opts.MaxMembers > maxClanMembers {
+		problems = append(problems, fmt.Sprintf("members must be between 0 and %d", maxClanMembers))
+	}
+	if opts.MaxMembers > 0 && opts.MinMembers > opts.MaxMembers {
+		problems = append(problems, fmt.Sprintf("min members %d is more than max members %d",
+			opts.MinMembers, opts.MaxMembers))
+	}
+	if opts.MinClanPoints < 0 || opts.MinClanLevel < 0 {
+		problems = append(problems, "min clan points and level can not be negative")
+	}
+
+	if cs.location != "" {
+		locations := cs.locations
+		if locations == nil {
+			locations = NewLocations(cs.service.client)
+		}
+		location, err := locations.Resolve(cs.location)
+		if err != nil {
+			problems = append(problems, err.Error())
+		} else {
+			opts.LocationId = location.Id
+		}
+	}
+
+	opts.Labels = append([]int32{}, opts.Labels...)
+	for _, name := range cs.labels {
+		label, err := ParseClanLabel(name)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		opts.Labels = append(opts.Labels, int32(label))
+	}
+
+	if cs.name == "" && opts.WarFrequency == "" && opts.LocationId == 0 && opts.MinMembers == 0 &&
+		opts.MaxMembers == 0 && opts.MinClanPoints == 0 && opts.MinClanLevel == 0 && len(opts.Labels) == 0 {
+		problems = append(problems, "a name or at least one filter is needed")
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid clan search: %s", strings.Join(problems, ", "))
+	}
+	return &opts, nil
+}
+
+// Run will validate and run the search, following the paging cursors until the
+// limit is reached, there are no more results or the page limit is reached
+func (cs *ClanSearch) Run() ([]*Clan, error) {
+	opts, err := cs.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	var clans []*Clan
+	for page := 0; cs.maxPages <= 0 || page < cs.maxPages; page++ {
+		if cs.throttle != nil {
+			cs.throttle()
+		}
+		results, paging, err := cs.service.SearchPage(cs.name, opts)
+		if err != nil {
+			return clans, err
+		}
+
+		for _, clan := range results {
+			if !cs.match(clan) {
+				continue
+			}
+			clans = append(clans, clan)
+			if cs.limit > 0 && len(clans) >= cs.limit {
+				return clans, nil
+			}
+		}
+
+		if paging.Cursors.After == "" || len(results) == 0 {
+			break
+		}
+		opts.After = paging.Cursors.After
+	}
+
+	return clans, nil
+}
+
+func (cs *ClanSearch) match(clan *Clan) bool {
+	for _, fn := range cs.filters {
+		if !fn(clan) {
+			return false
+		}
+	}
+	return true
+}
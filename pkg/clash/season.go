@@ -0,0 +1,40 @@
+package goclash
+
+import "time"
+
+// seasonResetHour is the hour in UTC the trophy season ends
+const seasonResetHour = 5
+
+// SeasonIdFormat is the layout of season ids such as 2020-05 used by the API
+const SeasonIdFormat = "2006-01"
+
+// seasonEndOfMonth will get when the trophy season ends in a month, which is on the
+// last Monday of the month
+func seasonEndOfMonth(year int, month time.Month) time.Time {
+	last := time.Date(year, month+1, 0, seasonResetHour, 0, 0, 0, time.UTC)
+	offset := (int(last.Weekday()) - int(time.Monday) + 7) % 7
+	return last.AddDate(0, 0, -offset)
+}
+
+// SeasonEnd will get when the trophy season running at t ends. Donation counters
+// and legend league trophies are reset at the end of a season
+func SeasonEnd(t time.Time) time.Time {
+	t = t.UTC()
+	end := seasonEndOfMonth(t.Year(), t.Month())
+	if !t.Before(end) {
+		end = seasonEndOfMonth(t.Year(), t.Month()+1)
+	}
+	return end
+}
+
+// SeasonStart will get when the trophy season running at t started
+func SeasonStart(t time.Time) time.Time {
+	end := SeasonEnd(t)
+	return seasonEndOfMonth(end.Year(), end.Month()-1)
+}
+
+// SeasonId will get the id of the trophy season running at t in the same format as
+// the API, e.g. 2020-05
+func SeasonId(t time.Time) string {
+	return SeasonEnd(t).Format(SeasonIdFormat)
+}
@@ -0,0 +1,85 @@
+package goclash
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// tagAlphabet holds every character that can appear in a tag. The position of a
+// character is its value when a tag is read as a base 14 number
+const tagAlphabet = "0289PYLQGRJCUV"
+
+// maxTagId is the largest id whose low part still fits in an int32
+const maxTagId = int64(math.MaxInt32)<<8 | 0xff
+
+var errEmptyTag = errors.New("tag was empty")
+
+// Tag is a player, clan or war tag such as #RQ8JLVQ
+type Tag string
+
+// ParseTag will normalise a tag the way it is often typed or pasted by players and
+// validate it. Whitespace is removed, letters are uppercased, the letter O is read
+// as a zero and a missing # is added
+func ParseTag(s string) (Tag, error) {
+	tag := strings.ToUpper(strings.Join(strings.Fields(s), ""))
+	tag = strings.TrimPrefix(tag, "#")
+	tag = strings.Replace(tag, "O", "0", -1)
+
+	if tag == "" {
+		return "", errEmptyTag
+	}
+
+	for _, r := range tag {
+		if !strings.ContainsRune(tagAlphabet, r) {
+			return "", fmt.Errorf("tag %q was not valid: %q is not a tag character", s, r)
+		}
+	}
+
+	return Tag("#" + tag), nil
+}
+
+// String returns the tag including the leading #
+func (t Tag) String() string {
+	return string(t)
+}
+
+// Id will convert the tag into the high and low parts of the numeric id the game
+// uses internally. Tags too long to fit in the id are an error
+func (t Tag) Id() (high int32, low int32, err error) {
+	tag := strings.TrimPrefix(string(t), "#")
+	if tag == "" {
+		return 0, 0, errEmptyTag
+	}
+
+	var id int64
+	for _, r := range tag {
+		i := strings.IndexRune(tagAlphabet, r)
+		if i < 0 {
+			return 0, 0, fmt.Errorf("tag %q was not valid: %q is not a tag character", t, r)
+		}
+		id = id*int64(len(tagAlphabet)) + int64(i)
+		if id > maxTagId {
+			return 0, 0, fmt.Errorf("tag %q was not valid: it is too long to be an id", t)
+		}
+	}
+
+	return int32(id & 0xff), int32(id >> 8), nil
+}
+
+// TagFromId will convert the high and low parts of a numeric id into a tag
+func TagFromId(high int32, low int32) Tag {
+	id := int64(low)<<8 | int64(high&0xff)
+	if id == 0 {
+		return Tag("#0")
+	}
+
+	var tag []byte
+	for id > 0 {
+		tag = append([]byte{tagAlphabet[id%int64(len(tagAlphabet))]}, tag...)
+		id /= int64(len(tagAlphabet))
+	}
+
+	return Tag("#" + string(tag))
+}
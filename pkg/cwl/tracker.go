@@ -0,0 +1,262 @@
+package cwl
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/war"
+)
+
+const (
+	// placeholderTag is the war tag given to rounds that have not been drawn yet
+	placeholderTag = "#0"
+	// WinBonus is the amount of stars a clan is awarded for winning a league war
+	WinBonus = 10
+	// attacksPerMember is the amount of attacks each member gets in a league war
+	attacksPerMember = 1
+)
+
+// Tracker will follow a clans progress through a clan war league season
+type Tracker struct {
+	client  *goclash.Client
+	clanTag string
+}
+
+// Season holds everything known about a clan war league season for a clan
+type Season struct {
+	Season    string
+	State     string
+	ClanTag   string
+	Group     *goclash.LeagueGroup
+	Rounds    []*Round
+	Standings []*Standing
+	Members   []*MemberStats
+}
+
+// Round holds the wars of a single league day
+type Round struct {
+	Day  int
+	Wars []*goclash.War
+	// Our is the war our clan is fighting in this round, seen from our side. It is
+	// nil if the round has not been drawn yet
+	Our *goclash.War
+}
+
+// Standing holds a clans position in the league group
+type Standing struct {
+	Rank        int
+	Tag         string
+	Name        string
+	Stars       int
+	Destruction float32
+	Wins        int
+	Ties        int
+	Losses      int
+}
+
+// MemberStats holds a members performance across every league day
+type MemberStats struct {
+	Tag                  string
+	Name                 string
+	TownhallLevel        int
+	Days                 int
+	AttacksUsed          int
+	AttacksAvailable     int
+	Stars                int
+	Destruction          float32
+	Triples              int
+	DefencesReceived     int
+	StarsConceded        int
+	DestructionOnDefence float32
+	// Reports holds the war report of each day the member was in the line up
+	Reports []*war.MemberReport
+}
+
+// NewTracker will create a new Tracker for a clan
+func NewTracker(client *goclash.Client, clanTag string) *Tracker {
+	return &Tracker{
+		client:  client,
+		clanTag: clanTag,
+	}
+}
+
+// Season will fetch the current league group of the clan and every war that has
+// been drawn so far, then compute the group standings and member statistics
+func (t *Tracker) Season() (*Season, error) {
+	clanTag, err := goclash.ParseTag(t.clanTag)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := t.client.Clan.GetLeagueGroup(clanTag.String())
+	if err != nil {
+		return nil, fmt.Errorf("could not get league group: %s", err.Error())
+	}
+
+	season := Season{
+		Season:  group.Season,
+		State:   group.State,
+		ClanTag: clanTag.String(),
+		Group:   group,
+		Rounds:  make([]*Round, 0, len(group.Rounds)),
+	}
+
+	for i, groupRound := range group.Rounds {
+		round := Round{Day: i + 1}
+		for _, warTag := range groupRound.Tags {
+			if warTag == placeholderTag {
+				continue
+			}
+
+			leagueWar, err := t.client.Clan.GetWarLeagueWar(warTag)
+			if err != nil {
+				return nil, fmt.Errorf("could not get war %s for day %d: %s", warTag, round.Day,
+					err.Error())
+			}
+			if leagueWar.AttacksPerMember == 0 {
+				leagueWar.AttacksPerMember = attacksPerMember
+			}
+
+			round.Wars = append(round.Wars, leagueWar)
+			if our := fromSide(leagueWar, clanTag.String()); our != nil {
+				round.Our = our
+			}
+		}
+		season.Rounds = append(season.Rounds, &round)
+	}
+
+	season.Standings = Standings(group, season.Rounds)
+	season.Members = Members(season.Rounds)
+
+	return &season, nil
+}
+
+// fromSide will return the war as seen from the clan with the given tag or nil if
+// the clan is not part of the war
+func fromSide(w *goclash.War, clanTag string) *goclash.War {
+	switch {
+	case w.Clan.Tag == clanTag:
+		return w
+	case w.OpponentClan.Tag == clanTag:
+		swapped := *w
+		swapped.Clan, swapped.OpponentClan = w.OpponentClan, w.Clan
+		return &swapped
+	}
+	return nil
+}
+
+// Standings will compute the group standings from the wars fought so far. Win
+// bonuses are only awarded once a war has ended
+func Standings(group *goclash.LeagueGroup, rounds []*Round) []*Standing {
+	byTag := make(map[string]*Standing, len(group.Clans))
+	standings := make([]*Standing, 0, len(group.Clans))
+	for _, clan := range group.Clans {
+		standing := Standing{Tag: clan.Tag, Name: clan.Name}
+		byTag[clan.Tag] = &standing
+		standings = append(standings, &standing)
+	}
+
+	for _, round := range rounds {
+		for _, w := range round.Wars {
+			clan, opponent := byTag[w.Clan.Tag], byTag[w.OpponentClan.Tag]
+			if clan == nil || opponent == nil {
+				continue
+			}
+
+			clan.Stars += w.Clan.Stars
+			clan.Destruction += w.Clan.DestructionPercentage * float32(w.TeamSize)
+			opponent.Stars += w.OpponentClan.Stars
+			opponent.Destruction += w.OpponentClan.DestructionPercentage * float32(w.TeamSize)
+
+			if w.State != "warEnded" {
+				continue
+			}
+
+			switch compareClans(&w.Clan, &w.OpponentClan) {
+			case 1:
+				clan.Stars += WinBonus
+				clan.Wins++
+				opponent.Losses++
+			case -1:
+				opponent.Stars += WinBonus
+				opponent.Wins++
+				clan.Losses++
+			default:
+				clan.Ties++
+				opponent.Ties++
+			}
+		}
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].Stars != standings[j].Stars {
+			return standings[i].Stars > standings[j].Stars
+		}
+		return standings[i].Destruction > standings[j].Destruction
+	})
+	for i, standing := range standings {
+		standing.Rank = i + 1
+	}
+
+	return standings
+}
+
+// compareClans returns 1 if a won the war, -1 if b won the war and 0 for a tie
+func compareClans(a, b *goclash.WarClan) int {
+	switch {
+	case a.Stars > b.Stars:
+		return 1
+	case a.Stars < b.Stars:
+		return -1
+	case a.DestructionPercentage > b.DestructionPercentage:
+		return 1
+	case a.DestructionPercentage < b.DestructionPercentage:
+		return -1
+	}
+	return 0
+}
+
+// Members will compute the statistics of our clans members across every round
+func Members(rounds []*Round) []*MemberStats {
+	byTag := make(map[string]*MemberStats)
+	members := make([]*MemberStats, 0)
+
+	for _, round := range rounds {
+		if round.Our == nil {
+			continue
+		}
+
+		report := war.NewReport(round.Our)
+		for _, mr := range report.Clan.Members {
+			stats, ok := byTag[mr.Tag]
+			if !ok {
+				stats = &MemberStats{Tag: mr.Tag}
+				byTag[mr.Tag] = stats
+				members = append(members, stats)
+			}
+
+			stats.Name = mr.Name
+			stats.TownhallLevel = mr.TownhallLevel
+			stats.Days++
+			stats.AttacksUsed += mr.AttacksUsed
+			stats.AttacksAvailable += mr.AttacksAvailable
+			stats.Stars += mr.Stars
+			stats.Destruction += mr.Destruction
+			stats.Triples += mr.Triples
+			stats.DefencesReceived += mr.Defence.AttacksReceived
+			stats.StarsConceded += mr.Defence.BestStars
+			stats.DestructionOnDefence += mr.Defence.BestDestruction
+			stats.Reports = append(stats.Reports, mr)
+		}
+	}
+
+	sort.SliceStable(members, func(i, j int) bool {
+		if members[i].Stars != members[j].Stars {
+			return members[i].Stars > members[j].Stars
+		}
+		return members[i].Destruction > members[j].Destruction
+	})
+
+	return members
+}
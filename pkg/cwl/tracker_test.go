@@ -0,0 +1,112 @@
+package cwl_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/cwl"
+)
+
+var responses = map[string]string{
+	"/clans/#UV/currentwar/leaguegroup": `{
+		"state": "inWar", "season": "2026-10",
+		"clans": [
+			{"tag": "#UV", "name": "Us"}, {"tag": "#8", "name": "Bravo"},
+			{"tag": "#C", "name": "Charlie"}, {"tag": "#9", "name": "Delta"}
+		],
+		"rounds": [
+			{"warTags": ["#P2", "#P8"]},
+			{"warTags": ["#P9", "#PP"]},
+			{"warTags": ["#0", "#0"]}
+		]
+	}`,
+	"/clanwarleagues/wars/#P2": `{
+		"state": "warEnded", "teamSize": 1,
+		"clan": {"tag": "#8", "stars": 2, "destructionPercentage": 80,
+			"members": [{"tag": "#B1", "mapPosition": 1, "townhallLevel": 12,
+				"opponentAttacks": 1, "bestOpponentAttack": {"stars": 3, "destructionPercentage": 100},
+				"attacks": [{"order": 1, "attackerTag": "#B1", "defenderTag": "#U1", "stars": 2, "destructionPercentage": 80}]}]},
+		"opponent": {"tag": "#UV", "stars": 3, "destructionPercentage": 100,
+			"members": [{"tag": "#U1", "name": "one", "mapPosition": 1, "townhallLevel": 12,
+				"opponentAttacks": 1, "bestOpponentAttack": {"stars": 2, "destructionPercentage": 80},
+				"attacks": [{"order": 2, "attackerTag": "#U1", "defenderTag": "#B1", "stars": 3, "destructionPercentage": 100}]}]}
+	}`,
+	"/clanwarleagues/wars/#P8": `{
+		"state": "warEnded", "teamSize": 1,
+		"clan": {"tag": "#C", "stars": 1, "destructionPercentage": 40},
+		"opponent": {"tag": "#9", "stars": 1, "destructionPercentage": 50}
+	}`,
+	"/clanwarleagues/wars/#P9": `{
+		"state": "inWar", "teamSize": 1,
+		"clan": {"tag": "#UV", "stars": 1, "destructionPercentage": 60,
+			"members": [{"tag": "#U1", "name": "one", "mapPosition": 1, "townhallLevel": 12,
+				"attacks": [{"order": 1, "attackerTag": "#U1", "defenderTag": "#C1", "stars": 1, "destructionPercentage": 60}]}]},
+		"opponent": {"tag": "#C", "stars": 0, "destructionPercentage": 0,
+			"members": [{"tag": "#C1", "mapPosition": 1, "townhallLevel": 12, "opponentAttacks": 1,
+				"bestOpponentAttack": {"stars": 1, "destructionPercentage": 60}}]}
+	}`,
+	"/clanwarleagues/wars/#PP": `{
+		"state": "inWar", "teamSize": 1,
+		"clan": {"tag": "#8", "stars": 0, "destructionPercentage": 0},
+		"opponent": {"tag": "#9", "stars": 0, "destructionPercentage": 0}
+	}`,
+}
+
+func testClient(t *testing.T) (*goclash.Client, *httptest.Server) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"reason": "notFound"}`))
+			return
+		}
+		w.Write([]byte(body))
+	}))
+
+	client, err := goclash.NewClient("token")
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	return client, server
+}
+
+func TestTrackerSeason(t *testing.T) {
+	client, server := testClient(t)
+	defer server.Close()
+
+	season, err := cwl.NewTracker(client, "#UV").Season()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(season.Rounds) != 3 || len(season.Rounds[2].Wars) != 0 {
+		t.Fatalf("expected the undrawn round to have no wars, got %+v", season.Rounds)
+	}
+	if our := season.Rounds[0].Our; our == nil || our.Clan.Tag != "#UV" {
+		t.Fatal("expected our war on day one to be seen from our side")
+	}
+
+	first := season.Standings[0]
+	if first.Tag != "#UV" || first.Stars != 3+cwl.WinBonus+1 || first.Wins != 1 {
+		t.Errorf("unexpected leader: %+v", first)
+	}
+	for _, standing := range season.Standings {
+		if standing.Tag == "#9" && standing.Wins != 1 {
+			t.Errorf("Delta should have won on destruction: %+v", standing)
+		}
+	}
+
+	if len(season.Members) != 1 {
+		t.Fatalf("Wanted: 1 member\tGot: %d", len(season.Members))
+	}
+	member := season.Members[0]
+	if member.Days != 2 || member.Stars != 4 || member.Triples != 1 || member.AttacksAvailable != 2 {
+		t.Errorf("unexpected member stats: %+v", member)
+	}
+}
@@ -0,0 +1,209 @@
+package donations
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+)
+
+// Snapshot holds the donation counters of every member of a clan at a point in time
+type Snapshot struct {
+	ClanTag string            `json:"clanTag"`
+	Time    time.Time         `json:"time"`
+	Season  string            `json:"season"`
+	Members []*MemberSnapshot `json:"members"`
+}
+
+// MemberSnapshot holds a members donation counters
+type MemberSnapshot struct {
+	Tag       string `json:"tag"`
+	Name      string `json:"name"`
+	Donations int    `json:"donations"`
+	Received  int    `json:"received"`
+}
+
+// NewSnapshot will create a snapshot from the members of a clan taken at t
+func NewSnapshot(clanTag string, t time.Time, members []*goclash.Member) *Snapshot {
+	snapshot := Snapshot{
+		ClanTag: clanTag,
+		Time:    t.UTC(),
+		Season:  goclash.SeasonId(t),
+		Members: make([]*MemberSnapshot, 0, len(members)),
+	}
+	for _, member := range members {
+		snapshot.Members = append(snapshot.Members, &MemberSnapshot{
+			Tag:       member.Tag,
+			Name:      member.Name,
+			Donations: member.Donations,
+			Received:  member.DonationsReceived,
+		})
+	}
+	return &snapshot
+}
+
+// MemberDonations holds a members donations over a season
+type MemberDonations struct {
+	Tag       string `json:"tag"`
+	Name      string `json:"name"`
+	Donations int    `json:"donations"`
+	Received  int    `json:"received"`
+	// CarriedDonations and CarriedReceived hold counters from before a reset that
+	// happened within the season
+	CarriedDonations int `json:"carriedDonations"`
+	CarriedReceived  int `json:"carriedReceived"`
+}
+
+// Ratio is the amount of troops donated for every troop received. Members that
+// received nothing have a ratio equal to their donations
+func (md *MemberDonations) Ratio() float64 {
+	if md.Received == 0 {
+		return float64(md.Donations)
+	}
+	return float64(md.Donations) / float64(md.Received)
+}
+
+// Ledger keeps donation totals per season across snapshots. Counters are reset at
+// the end of each trophy season, so snapshots are grouped by season and a counter
+// that goes down within a season is treated as a reset
+type Ledger struct {
+	// Seasons is keyed by season id then member tag
+	Seasons map[string]map[string]*MemberDonations `json:"seasons"`
+}
+
+// NewLedger will create an empty Ledger
+func NewLedger() *Ledger {
+	return &Ledger{Seasons: make(map[string]map[string]*MemberDonations)}
+}
+
+// Add will record a snapshot in the ledger
+func (l *Ledger) Add(s *Snapshot) {
+	if l.Seasons == nil {
+		l.Seasons = make(map[string]map[string]*MemberDonations)
+	}
+	season := s.Season
+	if season == "" {
+		season = goclash.SeasonId(s.Time)
+	}
+
+	members, ok := l.Seasons[season]
+	if !ok {
+		members = make(map[string]*MemberDonations)
+		l.Seasons[season] = members
+	}
+
+	for _, ms := range s.Members {
+		md, ok := members[ms.Tag]
+		if !ok {
+			md = &MemberDonations{Tag: ms.Tag}
+			members[ms.Tag] = md
+		}
+		md.Name = ms.Name
+
+		lastDonations := md.Donations - md.CarriedDonations
+		lastReceived := md.Received - md.CarriedReceived
+		if ms.Donations < lastDonations || ms.Received < lastReceived {
+			md.CarriedDonations += lastDonations
+			md.CarriedReceived += lastReceived
+		}
+		md.Donations = md.CarriedDonations + ms.Donations
+		md.Received = md.CarriedReceived + ms.Received
+	}
+}
+
+// Report holds the donations of every member over a season
+type Report struct {
+	Season string
+	// Members is sorted by donations, highest first
+	Members []*MemberDonations
+}
+
+// Season will create a report for a season, nil if the ledger has no snapshots of
+// the season
+func (l *Ledger) Season(id string) *Report {
+	members, ok := l.Seasons[id]
+	if !ok {
+		return nil
+	}
+
+	report := Report{Season: id, Members: make([]*MemberDonations, 0, len(members))}
+	for _, md := range members {
+		report.Members = append(report.Members, md)
+	}
+	sort.SliceStable(report.Members, func(i, j int) bool {
+		if report.Members[i].Donations == report.Members[j].Donations {
+			return report.Members[i].Tag < report.Members[j].Tag
+		}
+		return report.Members[i].Donations > report.Members[j].Donations
+	})
+
+	return &report
+}
+
+// Leaderboard will get the top n donators of the season, every member if n is 0
+func (r *Report) Leaderboard(n int) []*MemberDonations {
+	if n <= 0 || n > len(r.Members) {
+		n = len(r.Members)
+	}
+	return r.Members[:n]
+}
+
+// Leechers will list members that received at least minReceived troops and have a
+// ratio below maxRatio
+func (r *Report) Leechers(minReceived int, maxRatio float64) []*MemberDonations {
+	var leechers []*MemberDonations
+	for _, md := range r.Members {
+		if md.Received >= minReceived && md.Ratio() < maxRatio {
+			leechers = append(leechers, md)
+		}
+	}
+	return leechers
+}
+
+// Givers will list members that donated at least minDonations troops and have a
+// ratio of at least minRatio
+func (r *Report) Givers(minDonations int, minRatio float64) []*MemberDonations {
+	var givers []*MemberDonations
+	for _, md := range r.Members {
+		if md.Donations >= minDonations && md.Ratio() >= minRatio {
+			givers = append(givers, md)
+		}
+	}
+	return givers
+}
+
+// Tracker takes donation snapshots of a clan and adds them to a Ledger
+type Tracker struct {
+	client  *goclash.Client
+	clanTag string
+
+	Ledger *Ledger
+}
+
+// NewTracker will create a new Tracker for a clan with an empty Ledger
+func NewTracker(client *goclash.Client, clanTag string) *Tracker {
+	return &Tracker{
+		client:  client,
+		clanTag: clanTag,
+		Ledger:  NewLedger(),
+	}
+}
+
+// Poll will get the members of the clan and add their counters to the ledger
+func (t *Tracker) Poll() (*Snapshot, error) {
+	clanTag, err := goclash.ParseTag(t.clanTag)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := t.client.Clan.GetMembers(clanTag.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not get members: %s", err.Error())
+	}
+
+	snapshot := NewSnapshot(clanTag.String(), time.Now(), members)
+	t.Ledger.Add(snapshot)
+
+	return snapshot, nil
+}
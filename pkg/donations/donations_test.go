@@ -0,0 +1,47 @@
+package donations_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/donations"
+)
+
+func TestLedger(t *testing.T) {
+	may := time.Date(2020, 5, 10, 0, 0, 0, 0, time.UTC)
+	ledger := donations.NewLedger()
+	for i, counters := range [][2][2]int{
+		{{100, 10}, {5, 300}},
+		{{400, 20}, {10, 900}},
+		// counters reset without the season changing
+		{{50, 0}, {20, 950}},
+	} {
+		ledger.Add(donations.NewSnapshot("#2PP", may.Add(time.Duration(i)*time.Hour), []*goclash.Member{
+			{Tag: "#P2", Name: "giver", Donations: counters[0][0], DonationsReceived: counters[0][1]},
+			{Tag: "#P8", Name: "leecher", Donations: counters[1][0], DonationsReceived: counters[1][1]},
+		}))
+	}
+	ledger.Add(donations.NewSnapshot("#2PP", time.Date(2020, 5, 26, 0, 0, 0, 0, time.UTC), []*goclash.Member{
+		{Tag: "#P2", Name: "giver", Donations: 5},
+	}))
+
+	report := ledger.Season("2020-05")
+	if report == nil || len(report.Members) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	giver := report.Leaderboard(1)[0]
+	if giver.Tag != "#P2" || giver.Donations != 450 || giver.Received != 20 {
+		t.Errorf("unexpected top donator: %+v", giver)
+	}
+	if leechers := report.Leechers(100, 0.5); len(leechers) != 1 || leechers[0].Tag != "#P8" {
+		t.Errorf("unexpected leechers: %+v", leechers)
+	}
+	if givers := report.Givers(100, 2); len(givers) != 1 || givers[0].Tag != "#P2" {
+		t.Errorf("unexpected givers: %+v", givers)
+	}
+
+	if june := ledger.Season("2020-06"); june == nil || june.Members[0].Donations != 5 {
+		t.Errorf("expected the next season to start from zero: %+v", june)
+	}
+}
@@ -0,0 +1,355 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joshturge/goclash/pkg/clash"
+)
+
+// Table holds rows of exported values in a stable column order
+type Table struct {
+	Name    string
+	Columns []string
+	Numeric []bool
+	Rows    [][]string
+}
+
+// column describes a single field that can be exported from a value
+type column struct {
+	name    string
+	numeric bool
+	value   func(v interface{}) string
+}
+
+func text(name string, value func(v interface{}) string) column {
+	return column{name: name, value: value}
+}
+
+func number(name string, value func(v interface{}) int) column {
+	return column{name: name, numeric: true, value: func(v interface{}) string {
+		return strconv.Itoa(value(v))
+	}}
+}
+
+func decimal(name string, value func(v interface{}) float32) column {
+	return column{name: name, numeric: true, value: func(v interface{}) string {
+		return strconv.FormatFloat(float64(value(v)), 'f', -1, 32)
+	}}
+}
+
+// newTable will build a table from values using the selected fields. When no fields
+// are given every column is exported
+func newTable(name string, columns []column, values []interface{}, fields []string) (*Table, error) {
+	selected := columns
+	if len(fields) > 0 {
+		byName := make(map[string]column, len(columns))
+		for _, col := range columns {
+			byName[strings.ToLower(col.name)] = col
+		}
+
+		selected = make([]column, 0, len(fields))
+		for _, field := range fields {
+			col, ok := byName[strings.ToLower(field)]
+			if !ok {
+				return nil, fmt.Errorf("unknown field %q, available fields are: %s", field,
+					strings.Join(columnNames(columns), ", "))
+			}
+			selected = append(selected, col)
+		}
+	}
+
+	table := Table{
+		Name:    name,
+		Columns: columnNames(selected),
+		Numeric: make([]bool, len(selected)),
+		Rows:    make([][]string, 0, len(values)),
+	}
+	for i, col := range selected {
+		table.Numeric[i] = col.numeric
+	}
+	for _, v := range values {
+		row := make([]string, len(selected))
+		for i, col := range selected {
+			row[i] = col.value(v)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	return &table, nil
+}
+
+func columnNames(columns []column) []string {
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.name
+	}
+	return names
+}
+
+// New will build a table from any of the value types supported by this package
+func New(v interface{}, fields ...string) (*Table, error) {
+	switch t := v.(type) {
+	case []*goclash.Member:
+		return Members(t, fields...)
+	case []*goclash.PlayerRanking:
+		return PlayerRankings(t, fields...)
+	case []*goclash.ClanRanking:
+		return ClanRankings(t, fields...)
+	case []*goclash.WarLog:
+		return WarLogs(t, fields...)
+	case *goclash.War:
+		return WarAttacks(t, fields...)
+	}
+	return nil, fmt.Errorf("values of type %T can not be exported", v)
+}
+
+// WriteCSV will write the table as csv to w with the column names as the first row
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := NewCSVWriter(w, t.Numeric)
+	if err := cw.Write(t.Columns); err != nil {
+		return fmt.Errorf("could not write csv header: %s", err.Error())
+	}
+	for _, row := range t.Rows {
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("could not write csv rows: %s", err.Error())
+		}
+	}
+	if err := cw.Flush(); err != nil {
+		return fmt.Errorf("could not write csv rows: %s", err.Error())
+	}
+	return nil
+}
+
+// CSVWriter writes csv rows one at a time, escaping text cells that would be run
+// as a formula by a spreadsheet
+type CSVWriter struct {
+	cw *csv.Writer
+	// numeric marks the columns that are left as they are
+	numeric []bool
+}
+
+// NewCSVWriter will create a CSVWriter. Columns marked as numeric are not escaped
+func NewCSVWriter(w io.Writer, numeric []bool) *CSVWriter {
+	return &CSVWriter{cw: csv.NewWriter(w), numeric: numeric}
+}
+
+// Write will write a single row. Rows are buffered until Flush is called
+func (w *CSVWriter) Write(row []string) error {
+	escaped := make([]string, len(row))
+	for i, cell := range row {
+		if i < len(w.numeric) && w.numeric[i] {
+			escaped[i] = cell
+			continue
+		}
+		escaped[i] = escapeFormula(cell)
+	}
+	return w.cw.Write(escaped)
+}
+
+// Flush will write any buffered rows to the underlying writer
+func (w *CSVWriter) Flush() error {
+	w.cw.Flush()
+	return w.cw.Error()
+}
+
+// escapeFormula will stop spreadsheets from running text that starts like a
+// formula, such as a player named =HYPERLINK(...), by prefixing it with a quote
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune(formulaPrefixes, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// UnescapeFormula will undo the escaping of a text cell written by a CSVWriter
+func UnescapeFormula(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
+
+// formulaPrefixes are the characters a spreadsheet treats as the start of a formula
+const formulaPrefixes = "=+-@\t\r"
+
+var memberColumns = []column{
+	text("tag", func(v interface{}) string { return v.(*goclash.Member).Tag }),
+	text("name", func(v interface{}) string { return v.(*goclash.Member).Name }),
+	text("role", func(v interface{}) string { return v.(*goclash.Member).Role }),
+	number("expLevel", func(v interface{}) int { return v.(*goclash.Member).ExpLevel }),
+	text("league", func(v interface{}) string { return v.(*goclash.Member).League.Name }),
+	number("trophies", func(v interface{}) int { return v.(*goclash.Member).Trophies }),
+	number("versusTrophies", func(v interface{}) int { return v.(*goclash.Member).VersusTrophies }),
+	number("clanRank", func(v interface{}) int { return v.(*goclash.Member).Rank }),
+	number("previousClanRank", func(v interface{}) int { return v.(*goclash.Member).PreviousRank }),
+	number("donations", func(v interface{}) int { return v.(*goclash.Member).Donations }),
+	number("donationsReceived", func(v interface{}) int { return v.(*goclash.Member).DonationsReceived }),
+}
+
+// Members will build a table of clan members
+func Members(members []*goclash.Member, fields ...string) (*Table, error) {
+	values := make([]interface{}, len(members))
+	for i, m := range members {
+		values[i] = m
+	}
+	return newTable("members", memberColumns, values, fields)
+}
+
+var playerRankingColumns = []column{
+	number("rank", func(v interface{}) int { return v.(*goclash.PlayerRanking).Rank }),
+	number("previousRank", func(v interface{}) int { return v.(*goclash.PlayerRanking).PreviousRank }),
+	text("tag", func(v interface{}) string { return v.(*goclash.PlayerRanking).Tag }),
+	text("name", func(v interface{}) string { return v.(*goclash.PlayerRanking).Name }),
+	number("expLevel", func(v interface{}) int { return v.(*goclash.PlayerRanking).ExpLevel }),
+	number("trophies", func(v interface{}) int { return v.(*goclash.PlayerRanking).Trophies }),
+	number("attackWins", func(v interface{}) int { return v.(*goclash.PlayerRanking).AttackWins }),
+	number("defenseWins", func(v interface{}) int { return v.(*goclash.PlayerRanking).DefenceWins }),
+	text("league", func(v interface{}) string { return v.(*goclash.PlayerRanking).League.Name }),
+	text("clanTag", func(v interface{}) string { return v.(*goclash.PlayerRanking).Clan.Tag }),
+	text("clanName", func(v interface{}) string { return v.(*goclash.PlayerRanking).Clan.Name }),
+}
+
+// PlayerRankings will build a table of player rankings
+func PlayerRankings(rankings []*goclash.PlayerRanking, fields ...string) (*Table, error) {
+	values := make([]interface{}, len(rankings))
+	for i, r := range rankings {
+		values[i] = r
+	}
+	return newTable("playerRankings", playerRankingColumns, values, fields)
+}
+
+var clanRankingColumns = []column{
+	number("rank", func(v interface{}) int { return v.(*goclash.ClanRanking).Rank }),
+	number("previousRank", func(v interface{}) int { return v.(*goclash.ClanRanking).PreviousRank }),
+	text("tag", func(v interface{}) string { return v.(*goclash.ClanRanking).Tag }),
+	text("name", func(v interface{}) string { return v.(*goclash.ClanRanking).Name }),
+	number("clanLevel", func(v interface{}) int { return v.(*goclash.ClanRanking).Level }),
+	number("members", func(v interface{}) int { return v.(*goclash.ClanRanking).MemberCount }),
+	number("clanPoints", func(v interface{}) int { return v.(*goclash.ClanRanking).Points }),
+	text("location", func(v interface{}) string { return v.(*goclash.ClanRanking).Location.Name }),
+}
+
+// ClanRankings will build a table of clan rankings
+func ClanRankings(rankings []*goclash.ClanRanking, fields ...string) (*Table, error) {
+	values := make([]interface{}, len(rankings))
+	for i, r := range rankings {
+		values[i] = r
+	}
+	return newTable("clanRankings", clanRankingColumns, values, fields)
+}
+
+var warLogColumns = []column{
+	text("endTime", func(v interface{}) string {
+		return v.(*goclash.WarLog).EndTime.UTC().Format("2006-01-02 15:04:05")
+	}),
+	text("result", func(v interface{}) string { return v.(*goclash.WarLog).Result }),
+	number("teamSize", func(v interface{}) int { return v.(*goclash.WarLog).TeamSize }),
+	text("clanTag", func(v interface{}) string { return v.(*goclash.WarLog).Clan.Tag }),
+	text("clanName", func(v interface{}) string { return v.(*goclash.WarLog).Clan.Name }),
+	number("clanStars", func(v interface{}) int { return v.(*goclash.WarLog).Clan.Stars }),
+	decimal("clanDestruction", func(v interface{}) float32 {
+		return v.(*goclash.WarLog).Clan.DestructionPercentage
+	}),
+	number("clanAttacks", func(v interface{}) int { return v.(*goclash.WarLog).Clan.Attacks }),
+	number("expEarned", func(v interface{}) int { return v.(*goclash.WarLog).Clan.ExpEarned }),
+	text("opponentTag", func(v interface{}) string { return v.(*goclash.WarLog).OpponentClan.Tag }),
+	text("opponentName", func(v interface{}) string { return v.(*goclash.WarLog).OpponentClan.Name }),
+	number("opponentStars", func(v interface{}) int { return v.(*goclash.WarLog).OpponentClan.Stars }),
+	decimal("opponentDestruction", func(v interface{}) float32 {
+		return v.(*goclash.WarLog).OpponentClan.DestructionPercentage
+	}),
+}
+
+// WarLogs will build a table of war log entries
+func WarLogs(logs []*goclash.WarLog, fields ...string) (*Table, error) {
+	values := make([]interface{}, len(logs))
+	for i, l := range logs {
+		values[i] = l
+	}
+	return newTable("warLog", warLogColumns, values, fields)
+}
+
+// AttackRow is a single attack of a war joined with its attacker and defender
+type AttackRow struct {
+	ClanTag  string
+	Attacker *goclash.WarMember
+	Defender *goclash.WarMember
+	Attack   *goclash.Attack
+}
+
+var attackColumns = []column{
+	number("order", func(v interface{}) int { return v.(*AttackRow).Attack.Order }),
+	text("clanTag", func(v interface{}) string { return v.(*AttackRow).ClanTag }),
+	text("attackerTag", func(v interface{}) string { return v.(*AttackRow).Attacker.Tag }),
+	text("attackerName", func(v interface{}) string { return v.(*AttackRow).Attacker.Name }),
+	number("attackerMapPosition", func(v interface{}) int { return v.(*AttackRow).Attacker.MapPosition }),
+	number("attackerTownhallLevel", func(v interface{}) int {
+		return v.(*AttackRow).Attacker.TownhallLevel
+	}),
+	text("defenderTag", func(v interface{}) string { return v.(*AttackRow).Defender.Tag }),
+	text("defenderName", func(v interface{}) string { return v.(*AttackRow).Defender.Name }),
+	number("defenderMapPosition", func(v interface{}) int { return v.(*AttackRow).Defender.MapPosition }),
+	number("defenderTownhallLevel", func(v interface{}) int {
+		return v.(*AttackRow).Defender.TownhallLevel
+	}),
+	number("stars", func(v interface{}) int { return v.(*AttackRow).Attack.Stars }),
+	decimal("destruction", func(v interface{}) float32 {
+		return v.(*AttackRow).Attack.DestructionPercentage
+	}),
+}
+
+// AttackRows will join every attack made in a war with its attacker and defender,
+// ordered by when the attacks were made
+func AttackRows(w *goclash.War) []*AttackRow {
+	rows := make([]*AttackRow, 0)
+	rows = appendAttackRows(rows, &w.Clan, &w.OpponentClan)
+	rows = appendAttackRows(rows, &w.OpponentClan, &w.Clan)
+
+	// attacks from both clans are interleaved so sort them back into order
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Attack.Order < rows[j].Attack.Order
+	})
+
+	return rows
+}
+
+func appendAttackRows(rows []*AttackRow, clan, opponent *goclash.WarClan) []*AttackRow {
+	defenders := make(map[string]*goclash.WarMember, len(opponent.Team))
+	for i := range opponent.Team {
+		defenders[opponent.Team[i].Tag] = &opponent.Team[i]
+	}
+
+	for i := range clan.Team {
+		attacker := &clan.Team[i]
+		for j := range attacker.Attacks {
+			attack := &attacker.Attacks[j]
+			defender, ok := defenders[attack.DefenderTag]
+			if !ok {
+				defender = &goclash.WarMember{Tag: attack.DefenderTag}
+			}
+			rows = append(rows, &AttackRow{
+				ClanTag:  clan.Tag,
+				Attacker: attacker,
+				Defender: defender,
+				Attack:   attack,
+			})
+		}
+	}
+
+	return rows
+}
+
+// WarAttacks will build a table with a row for every attack made in a war
+func WarAttacks(w *goclash.War, fields ...string) (*Table, error) {
+	rows := AttackRows(w)
+	values := make([]interface{}, len(rows))
+	for i, r := range rows {
+		values[i] = r
+	}
+	return newTable("attacks", attackColumns, values, fields)
+}
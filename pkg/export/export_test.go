@@ -0,0 +1,116 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/export"
+)
+
+var members = []*goclash.Member{
+	{Tag: "#AAA", Name: "alpha, the first", Role: "leader", Donations: 120, DonationsReceived: 40},
+	{Tag: "#BBB", Name: "bravo", Role: "member", Donations: 5},
+}
+
+func TestMembersCSV(t *testing.T) {
+	table, err := export.Members(members, "name", "Donations", "tag")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err = table.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "name,donations,tag\n\"alpha, the first\",120,#AAA\nbravo,5,#BBB\n"
+	if buf.String() != want {
+		t.Errorf("Wanted:\n%s\nGot:\n%s", want, buf.String())
+	}
+
+	if _, err = export.Members(members, "gems"); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestCSVFormulas(t *testing.T) {
+	table, err := export.Members([]*goclash.Member{
+		{Tag: "#AAA", Name: "=HYPERLINK(\"http://x\")", Donations: 1},
+		{Tag: "#BBB", Name: "@bravo", Trophies: -5},
+	}, "name", "trophies")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err = table.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "name,trophies\n\"'=HYPERLINK(\"\"http://x\"\")\",0\n'@bravo,-5\n"
+	if buf.String() != want {
+		t.Errorf("Wanted:\n%s\nGot:\n%s", want, buf.String())
+	}
+}
+
+func TestWarAttacks(t *testing.T) {
+	war := &goclash.War{
+		Clan: goclash.WarClan{Tag: "#US", Team: []goclash.WarMember{
+			{Tag: "#A", Name: "attacker", Attacks: []goclash.Attack{{Order: 2, DefenderTag: "#D", Stars: 3}}},
+		}},
+		OpponentClan: goclash.WarClan{Tag: "#THEM", Team: []goclash.WarMember{
+			{Tag: "#D", Name: "defender", Attacks: []goclash.Attack{{Order: 1, DefenderTag: "#A", Stars: 1}}},
+		}},
+	}
+
+	table, err := export.New(war, "order", "clanTag", "attackerName", "defenderName", "stars")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(table.Rows) != 2 {
+		t.Fatalf("Wanted: 2 rows\tGot: %d", len(table.Rows))
+	}
+	if got := strings.Join(table.Rows[0], ","); got != "1,#THEM,defender,attacker,1" {
+		t.Errorf("unexpected first attack: %s", got)
+	}
+}
+
+func TestMembersXLSX(t *testing.T) {
+	table, err := export.Members(members)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err = table.WriteXLSX(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sheet bytes.Buffer
+		sheet.ReadFrom(rc)
+		rc.Close()
+
+		if !strings.Contains(sheet.String(), `<c r="J2"><v>120</v></c>`) {
+			t.Errorf("donations should be a numeric cell:\n%s", sheet.String())
+		}
+		return
+	}
+	t.Error("workbook is missing its sheet")
+}
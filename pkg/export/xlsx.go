@@ -0,0 +1,114 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+)
+
+// WriteXLSX will write the table as a single sheet xlsx workbook to w. Numeric
+// columns are written as numbers so they can be used in formulas
+func (t *Table) WriteXLSX(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	name := t.Name
+	if name == "" {
+		name = "Sheet1"
+	}
+
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{"[Content_Types].xml", []byte(xlsxContentTypes)},
+		{"_rels/.rels", []byte(xlsxRels)},
+		{"xl/_rels/workbook.xml.rels", []byte(xlsxWorkbookRels)},
+		{"xl/workbook.xml", []byte(fmt.Sprintf(xlsxWorkbook, escapeXML(name)))},
+		{"xl/worksheets/sheet1.xml", t.sheetXML()},
+	}
+
+	for _, file := range files {
+		f, err := zw.Create(file.name)
+		if err != nil {
+			return fmt.Errorf("could not create %s: %s", file.name, err.Error())
+		}
+		if _, err = f.Write(file.content); err != nil {
+			return fmt.Errorf("could not write %s: %s", file.name, err.Error())
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("could not finish xlsx workbook: %s", err.Error())
+	}
+	return nil
+}
+
+func (t *Table) sheetXML() []byte {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	writeRow := func(row int, values []string, numeric []bool) {
+		fmt.Fprintf(&b, `<row r="%d">`, row)
+		for i, value := range values {
+			ref := cellRef(i, row)
+			if numeric != nil && numeric[i] && value != "" {
+				fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, value)
+				continue
+			}
+			fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`,
+				ref, escapeXML(value))
+		}
+		b.WriteString(`</row>`)
+	}
+
+	writeRow(1, t.Columns, nil)
+	for i, row := range t.Rows {
+		writeRow(i+2, row, t.Numeric)
+	}
+
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.Bytes()
+}
+
+// cellRef will convert a zero based column index and a row number into a cell
+// reference such as A1 or AB12
+func cellRef(col, row int) string {
+	var name []byte
+	for col >= 0 {
+		name = append([]byte{byte('A' + col%26)}, name...)
+		col = col/26 - 1
+	}
+	return string(name) + strconv.Itoa(row)
+}
+
+func escapeXML(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
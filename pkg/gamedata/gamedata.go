@@ -0,0 +1,214 @@
+// Package gamedata holds static Clash of Clans game data that the API does not
+// expose, such as housing space and the town hall each level needs. The data is
+// bundled as JSON and should be refreshed when the game is updated.
+//
+// The bundled catalogue covers the home village only: the troops, siege machines,
+// spells and heroes up to the Thrower, Troop Launcher and Minion Prince, every
+// super troop except the Super Yeti, the pets up to town hall 16, the first 19
+// pieces of equipment and the main defences. Level caps go up to town hall 16,
+// except the Thrower and Troop Launcher which only list their town hall 17
+// levels. Anything not covered is returned by Enrich without an Item.
+package gamedata
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/joshturge/goclash/pkg/clash"
+)
+
+// Category is the kind of game item
+type Category string
+
+// Categories of items in the catalogue
+const (
+	Troop        Category = "troop"
+	SiegeMachine Category = "siegeMachine"
+	Spell        Category = "spell"
+	Hero         Category = "hero"
+	Pet          Category = "pet"
+	Equipment    Category = "equipment"
+	Building     Category = "building"
+)
+
+//go:embed gamedata.json
+var bundled []byte
+
+// Level holds what it takes to upgrade an item to a level
+type Level struct {
+	Level int `json:"level"`
+	// Townhall is the lowest town hall level the item can be upgraded to this level at
+	Townhall int `json:"townhall"`
+}
+
+// Item is a troop, spell, hero, pet, piece of equipment or building
+type Item struct {
+	Name     string   `json:"name"`
+	Category Category `json:"category"`
+	Village  string   `json:"village"`
+	// LinkId is the id used for the item in army links. Troops and siege machines
+	// share ids, while spells, heroes, pets and equipment each have their own
+	LinkId       int `json:"linkId"`
+	HousingSpace int `json:"housingSpace"`
+	// SuperTroopOf is the troop a super troop is boosted from. Super troops share
+	// the level of their base troop and have no levels of their own
+	SuperTroopOf string `json:"superTroopOf"`
+	// Hero is the hero a piece of equipment belongs to
+	Hero   string  `json:"hero"`
+	Rarity string  `json:"rarity"`
+	Levels []Level `json:"levels"`
+}
+
+// MaxLevel is the highest level the item can be upgraded to
+func (i *Item) MaxLevel() int {
+	return len(i.Levels)
+}
+
+// UnlockTownhall is the town hall level the item is unlocked at
+func (i *Item) UnlockTownhall() int {
+	if len(i.Levels) == 0 {
+		return 0
+	}
+	return i.Levels[0].Townhall
+}
+
+// MaxLevelForTownhall is the highest level the item can be upgraded to at a town
+// hall level, 0 if the item is not unlocked yet
+func (i *Item) MaxLevelForTownhall(townhall int) int {
+	max := 0
+	for _, l := range i.Levels {
+		if l.Townhall <= townhall && l.Level > max {
+			max = l.Level
+		}
+	}
+	return max
+}
+
+// Level will get the requirements of a single level
+func (i *Item) Level(level int) (*Level, bool) {
+	if level < 1 || level > len(i.Levels) {
+		return nil, false
+	}
+	return &i.Levels[level-1], true
+}
+
+// Catalogue holds every item of the game data
+type Catalogue struct {
+	Items []*Item `json:"items"`
+
+	byName map[string]*Item
+}
+
+// Parse will read a catalogue from json in the same layout as the bundled data
+func Parse(r io.Reader) (*Catalogue, error) {
+	var c Catalogue
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return nil, fmt.Errorf("could not decode game data: %s", err.Error())
+	}
+
+	c.byName = make(map[string]*Item, len(c.Items))
+	for _, item := range c.Items {
+		for n, l := range item.Levels {
+			if l.Level != n+1 {
+				return nil, fmt.Errorf("%s has level %d where level %d was expected", item.Name,
+					l.Level, n+1)
+			}
+		}
+		c.byName[strings.ToLower(item.Name)] = item
+	}
+
+	return &c, nil
+}
+
+var (
+	defaultOnce      sync.Once
+	defaultCatalogue *Catalogue
+)
+
+// Default is the catalogue bundled with this package
+func Default() *Catalogue {
+	defaultOnce.Do(func() {
+		var err error
+		defaultCatalogue, err = Parse(strings.NewReader(string(bundled)))
+		if err != nil {
+			panic("gamedata: bundled game data is invalid: " + err.Error())
+		}
+	})
+	return defaultCatalogue
+}
+
+// Lookup will find an item by the name the API uses for it
+func (c *Catalogue) Lookup(name string) (*Item, bool) {
+	item, ok := c.byName[strings.ToLower(name)]
+	return item, ok
+}
+
+// ByCategory will list every item in a category
+func (c *Catalogue) ByCategory(category Category) []*Item {
+	var items []*Item
+	for _, item := range c.Items {
+		if item.Category == category {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ByLinkId will find a troop, siege machine or spell by the id used in army links.
+// Spells have their own ids so spell must be set when looking one up
+func (c *Catalogue) ByLinkId(id int, spell bool) (*Item, bool) {
+	if spell {
+		return c.ByCategoryLinkId(id, Spell)
+	}
+	return c.ByCategoryLinkId(id, Troop, SiegeMachine)
+}
+
+// ByCategoryLinkId will find an item in one of the categories by the id used in
+// army links
+func (c *Catalogue) ByCategoryLinkId(id int, categories ...Category) (*Item, bool) {
+	for _, item := range c.Items {
+		for _, category := range categories {
+			if item.Category == category && item.LinkId == id {
+				return item, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// PlayerItem is one of a players troops, heroes, spells, pets or equipment with
+// its game data
+type PlayerItem struct {
+	goclash.Troop
+	// Item is nil if the item is not in the catalogue
+	Item *Item
+}
+
+// MaxLevelForTownhall is the highest level the item can have at a town hall level
+func (pi *PlayerItem) MaxLevelForTownhall(townhall int) int {
+	if pi.Item == nil {
+		return pi.MaxLevel
+	}
+	return pi.Item.MaxLevelForTownhall(townhall)
+}
+
+// Enrich will pair every home village troop, hero, spell and piece of equipment of
+// a player with its game data
+func (c *Catalogue) Enrich(player *goclash.Player) []*PlayerItem {
+	var items []*PlayerItem
+	for _, group := range [][]goclash.Troop{player.Troops, player.Heros, player.Spells,
+		player.HeroEquipment} {
+		for _, troop := range group {
+			if troop.Village != "" && troop.Village != "home" {
+				continue
+			}
+			item, _ := c.Lookup(troop.Name)
+			items = append(items, &PlayerItem{Troop: troop, Item: item})
+		}
+	}
+	return items
+}
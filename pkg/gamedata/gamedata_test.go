@@ -0,0 +1,66 @@
+package gamedata_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/gamedata"
+)
+
+func TestCatalogue(t *testing.T) {
+	c := gamedata.Default()
+
+	king, ok := c.Lookup("barbarian king")
+	if !ok {
+		t.Fatal("expected the Barbarian King to be in the catalogue")
+	}
+	if king.Category != gamedata.Hero || king.UnlockTownhall() != 7 {
+		t.Errorf("unexpected item: %+v", king)
+	}
+	if max := king.MaxLevelForTownhall(6); max != 0 {
+		t.Errorf("Wanted: 0\tGot: %d", max)
+	}
+	if max := king.MaxLevelForTownhall(king.Levels[len(king.Levels)-1].Townhall); max != king.MaxLevel() {
+		t.Errorf("Wanted: %d\tGot: %d", king.MaxLevel(), max)
+	}
+
+	if item, ok := c.ByLinkId(9, true); !ok || item.Name != "Poison Spell" {
+		t.Errorf("unexpected spell for id 9: %+v", item)
+	}
+	if item, ok := c.ByLinkId(0, false); !ok || item.Name != "Barbarian" {
+		t.Errorf("unexpected troop for id 0: %+v", item)
+	}
+
+	items := c.Enrich(&goclash.Player{
+		Troops: []goclash.Troop{{Name: "Barbarian", Level: 5, Village: "home"},
+			{Name: "Raged Barbarian", Level: 3, Village: "builderBase"}},
+		Heros: []goclash.Troop{{Name: "Unreleased Hero", Level: 1, MaxLevel: 10, Village: "home"}},
+	})
+	if len(items) != 2 || items[0].Item == nil || items[1].Item != nil {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+	if max := items[1].MaxLevelForTownhall(10); max != 10 {
+		t.Errorf("expected unknown items to fall back to the API max level but got %d", max)
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	c := gamedata.Default()
+	for _, name := range []string{"Electro Titan", "Root Rider", "Druid", "Thrower", "Super Wizard",
+		"Super Hog Rider", "Minion Prince", "Overgrowth Spell", "Recall Spell", "Log Launcher",
+		"Flame Flinger", "Battle Drill", "Troop Launcher"} {
+		if _, ok := c.Lookup(name); !ok {
+			t.Errorf("expected %s to be in the catalogue", name)
+		}
+	}
+	if item, ok := c.ByLinkId(53, true); !ok || item.Name != "Recall Spell" {
+		t.Errorf("unexpected spell for id 53: %+v", item)
+	}
+}
+
+func TestParse(t *testing.T) {
+	if _, err := gamedata.Parse(strings.NewReader(`{"items": [{"name": "x", "levels": [{"level": 2}]}]}`)); err == nil {
+		t.Error("expected levels out of order to be rejected")
+	}
+}
@@ -0,0 +1,394 @@
+package legends
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/export"
+)
+
+// LegendLeagueId is the id of the legend league, the only league with season rankings
+const LegendLeagueId = 29000022
+
+const (
+	// DefaultPageSize is how many rankings are requested at a time
+	DefaultPageSize = 1000
+	// DefaultInterval is the time left between requests so the API rate limit is
+	// not reached
+	DefaultInterval = 100 * time.Millisecond
+	// DefaultRetries is how many times a page is retried before the crawl stops
+	DefaultRetries = 5
+)
+
+// Format is the format rankings are written in
+type Format string
+
+// Formats rankings can be written in
+const (
+	NDJSON Format = "ndjson"
+	CSV    Format = "csv"
+)
+
+// Ranking is a single row of a season's rankings
+type Ranking struct {
+	Season      string `json:"season"`
+	Rank        int    `json:"rank"`
+	Tag         string `json:"tag"`
+	Name        string `json:"name"`
+	ExpLevel    int    `json:"expLevel"`
+	Trophies    int    `json:"trophies"`
+	AttackWins  int    `json:"attackWins"`
+	DefenceWins int    `json:"defenseWins"`
+	ClanTag     string `json:"clanTag,omitempty"`
+	ClanName    string `json:"clanName,omitempty"`
+}
+
+var rankingColumns = []string{"season", "rank", "tag", "name", "expLevel", "trophies", "attackWins",
+	"defenseWins", "clanTag", "clanName"}
+
+// rankingNumeric marks the columns of rankingColumns that are numbers
+var rankingNumeric = []bool{false, true, false, false, true, true, true, true, false, false}
+
+func (r *Ranking) record() []string {
+	return []string{r.Season, strconv.Itoa(r.Rank), r.Tag, r.Name, strconv.Itoa(r.ExpLevel),
+		strconv.Itoa(r.Trophies), strconv.Itoa(r.AttackWins), strconv.Itoa(r.DefenceWins), r.ClanTag,
+		r.ClanName}
+}
+
+func parseRecord(record []string) (*Ranking, error) {
+	if len(record) != len(rankingColumns) {
+		return nil, fmt.Errorf("expected %d columns but got %d", len(rankingColumns), len(record))
+	}
+	var (
+		r = Ranking{Season: record[0], Tag: record[2], Name: export.UnescapeFormula(record[3]),
+			ClanTag: record[8], ClanName: export.UnescapeFormula(record[9])}
+		err  error
+		ints = []struct {
+			v *int
+			s string
+		}{{&r.Rank, record[1]}, {&r.ExpLevel, record[4]}, {&r.Trophies, record[5]},
+			{&r.AttackWins, record[6]}, {&r.DefenceWins, record[7]}}
+	)
+	for _, i := range ints {
+		if *i.v, err = strconv.Atoi(i.s); err != nil {
+			return nil, fmt.Errorf("could not parse %q: %s", i.s, err.Error())
+		}
+	}
+	return &r, nil
+}
+
+// Checkpoint records how far the crawl of a season got so it can be resumed
+type Checkpoint struct {
+	LeagueId int32  `json:"leagueId"`
+	Season   string `json:"season"`
+	Format   Format `json:"format"`
+	// After is the cursor of the next page
+	After string `json:"after"`
+	Rows  int    `json:"rows"`
+	// Offset is the size of the output after the last complete page. Anything
+	// written past it is discarded when the crawl is resumed
+	Offset int64 `json:"offset"`
+	Done   bool  `json:"done"`
+}
+
+// CheckpointPath is where the checkpoint of a crawl writing to output is kept
+func CheckpointPath(output string) string {
+	return output + ".checkpoint"
+}
+
+func loadCheckpoint(path string) (*Checkpoint, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read checkpoint: %s", err.Error())
+	}
+
+	var cp Checkpoint
+	if err = json.Unmarshal(b, &cp); err != nil {
+		return nil, fmt.Errorf("could not decode checkpoint: %s", err.Error())
+	}
+	return &cp, nil
+}
+
+// save will atomically replace the checkpoint file
+func (cp *Checkpoint) save(path string) error {
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("could not encode checkpoint: %s", err.Error())
+	}
+	if err = ioutil.WriteFile(path+".tmp", b, 0644); err != nil {
+		return fmt.Errorf("could not write checkpoint: %s", err.Error())
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Crawler walks every page of legend season rankings and writes them to disk
+type Crawler struct {
+	client *goclash.Client
+
+	LeagueId int32
+	PageSize int
+	// Interval is the least amount of time between two requests
+	Interval time.Duration
+	// Retries is how many times a failed page is retried, waiting twice as long as
+	// the last time between each attempt. Client errors such as a missing season
+	// are not retried, except for being rate limited
+	Retries int
+	Backoff time.Duration
+
+	last time.Time
+}
+
+// NewCrawler will create a new Crawler for the legend league
+func NewCrawler(client *goclash.Client) *Crawler {
+	return &Crawler{
+		client:   client,
+		LeagueId: LegendLeagueId,
+		PageSize: DefaultPageSize,
+		Interval: DefaultInterval,
+		Retries:  DefaultRetries,
+		Backoff:  time.Second,
+	}
+}
+
+// Crawl will write every ranking of a season to output. If a checkpoint of an
+// earlier crawl to output exists the crawl is resumed from it
+func (c *Crawler) Crawl(season, output string, format Format) (*Checkpoint, error) {
+	if format != NDJSON && format != CSV {
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+
+	cpPath := CheckpointPath(output)
+	cp, err := loadCheckpoint(cpPath)
+	if err != nil {
+		return nil, err
+	}
+	if cp == nil {
+		cp = &Checkpoint{LeagueId: c.LeagueId, Season: season, Format: format}
+	} else if cp.Season != season || cp.LeagueId != c.LeagueId || cp.Format != format {
+		return nil, fmt.Errorf("checkpoint %s is for a different crawl", cpPath)
+	}
+	if cp.Done {
+		return cp, nil
+	}
+
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("could not open output: %s", err.Error())
+	}
+	defer f.Close()
+
+	if err = f.Truncate(cp.Offset); err != nil {
+		return nil, fmt.Errorf("could not truncate output: %s", err.Error())
+	}
+	if _, err = f.Seek(cp.Offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("could not seek output: %s", err.Error())
+	}
+
+	w := bufio.NewWriter(f)
+	cw := export.NewCSVWriter(w, rankingNumeric)
+	if format == CSV && cp.Offset == 0 {
+		if err = cw.Write(rankingColumns); err != nil {
+			return nil, fmt.Errorf("could not write header: %s", err.Error())
+		}
+	}
+
+	for {
+		players, paging, err := c.page(season, cp.After)
+		if err != nil {
+			return cp, fmt.Errorf("could not get rankings after %d rows: %s", cp.Rows, err.Error())
+		}
+
+		for _, player := range players {
+			r := Ranking{
+				Season:      season,
+				Rank:        player.Rank,
+				Tag:         player.Tag,
+				Name:        player.Name,
+				ExpLevel:    player.ExpLevel,
+				Trophies:    player.Trophies,
+				AttackWins:  player.AttackWins,
+				DefenceWins: player.DefenceWins,
+				ClanTag:     player.Clan.Tag,
+				ClanName:    player.Clan.Name,
+			}
+			if format == CSV {
+				err = cw.Write(r.record())
+			} else {
+				err = writeJSON(w, &r)
+			}
+			if err != nil {
+				return cp, err
+			}
+		}
+
+		if err = cw.Flush(); err != nil {
+			return cp, fmt.Errorf("could not write rankings: %s", err.Error())
+		}
+		if err = w.Flush(); err != nil {
+			return cp, fmt.Errorf("could not write rankings: %s", err.Error())
+		}
+		offset, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return cp, fmt.Errorf("could not get output offset: %s", err.Error())
+		}
+
+		cp.Rows += len(players)
+		cp.Offset = offset
+		cp.After = paging.Cursors.After
+		cp.Done = cp.After == "" || len(players) == 0
+		if err = cp.save(cpPath); err != nil {
+			return cp, err
+		}
+		if cp.Done {
+			return cp, nil
+		}
+	}
+}
+
+// CrawlAll will crawl every season of the league into dir, one file per season.
+// Seasons that were already crawled are skipped
+func (c *Crawler) CrawlAll(dir string, format Format) ([]*Checkpoint, error) {
+	c.wait()
+	seasons, err := c.client.League.GetSeasons(c.LeagueId, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not get seasons: %s", err.Error())
+	}
+
+	checkpoints := make([]*Checkpoint, 0, len(seasons))
+	for _, season := range seasons {
+		cp, err := c.Crawl(season.Id, filepath.Join(dir, season.Id+"."+string(format)), format)
+		if err != nil {
+			return checkpoints, fmt.Errorf("could not crawl season %s: %s", season.Id, err.Error())
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+	return checkpoints, nil
+}
+
+// page will get a page of rankings, retrying with backoff if the request fails
+func (c *Crawler) page(season, after string) ([]*goclash.LegendSeasonPlayer, *goclash.Paging, error) {
+	backoff := c.Backoff
+	for attempt := 0; ; attempt++ {
+		c.wait()
+		players, paging, err := c.client.League.GetSeasonRankingsPage(c.LeagueId, season,
+			&goclash.Control{Limit: c.PageSize, After: after})
+		if err == nil || attempt >= c.Retries || !retryable(err) {
+			return players, paging, err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// retryable reports whether a failed request may succeed if it is sent again.
+// Client errors will fail the same way every time, unless the client was rate
+// limited
+func retryable(err error) bool {
+	var errResp *goclash.ErrorResponse
+	if !errors.As(err, &errResp) {
+		return true
+	}
+	code := errResp.StatusCode
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// wait will sleep until Interval has passed since the last request
+func (c *Crawler) wait() {
+	if wait := c.Interval - time.Since(c.last); wait > 0 {
+		time.Sleep(wait)
+	}
+	c.last = time.Now()
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		return fmt.Errorf("could not write row: %s", err.Error())
+	}
+	return nil
+}
+
+// ReadRankings will read rankings written by a Crawler. The format is chosen by
+// the extension of the file
+func ReadRankings(path string, fn func(*Ranking) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("could not open rankings: %s", err.Error())
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), "."+string(CSV)) {
+		r := csv.NewReader(bufio.NewReader(f))
+		if _, err = r.Read(); err != nil {
+			return fmt.Errorf("could not read header: %s", err.Error())
+		}
+		for {
+			record, err := r.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("could not read row: %s", err.Error())
+			}
+			ranking, err := parseRecord(record)
+			if err != nil {
+				return err
+			}
+			if err = fn(ranking); err != nil {
+				return err
+			}
+		}
+	}
+
+	decoder := json.NewDecoder(bufio.NewReader(f))
+	for {
+		var ranking Ranking
+		err := decoder.Decode(&ranking)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not read row: %s", err.Error())
+		}
+		if err = fn(&ranking); err != nil {
+			return err
+		}
+	}
+}
+
+// FindPlayer will look through crawled rankings for a player and return their
+// ranking in every season they finished in, in the order of paths
+func FindPlayer(tag string, paths ...string) ([]*Ranking, error) {
+	playerTag, err := goclash.ParseTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*Ranking
+	for _, path := range paths {
+		err := ReadRankings(path, func(r *Ranking) error {
+			if r.Tag == playerTag.String() {
+				found = append(found, r)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not search %s: %s", path, err.Error())
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,151 @@
+package legends_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/legends"
+)
+
+var tags = []string{"#P2", "#P8", "#P9", "#PP", "#PY"}
+
+func rankingsServer(fail *bool, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if r.URL.Path != "/leagues/29000022/seasons/2020-05" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"reason": "notFound"}`))
+			return
+		}
+
+		start := 0
+		if after := r.URL.Query().Get("after"); after != "" {
+			fmt.Sscan(after, &start)
+			if *fail {
+				*fail = false
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(`{"reason": "requestThrottled"}`))
+				return
+			}
+		}
+		end := start + 2
+		if end > len(tags) {
+			end = len(tags)
+		}
+
+		var items []string
+		for i := start; i < end; i++ {
+			items = append(items, fmt.Sprintf(`{"tag": %q, "name": "=player, %d", "rank": %d, "trophies": %d,
+				"clan": {"tag": "#2PP", "name": "clan"}}`, tags[i], i, i+1, 6000-i))
+		}
+		after := ""
+		if end < len(tags) {
+			after = fmt.Sprint(end)
+		}
+		fmt.Fprintf(w, `{"items": [%s], "paging": {"cursors": {"after": %q}}}`, strings.Join(items, ","), after)
+	}))
+}
+
+func TestCrawler(t *testing.T) {
+	fail := true
+	var requests int
+	server := rankingsServer(&fail, &requests)
+	defer server.Close()
+
+	client, err := goclash.NewClient("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	client.SetLogger(log.New(ioutil.Discard, "", 0))
+
+	dir, err := ioutil.TempDir("", "legends")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	crawler := legends.NewCrawler(client)
+	crawler.PageSize, crawler.Interval, crawler.Retries = 2, 0, 0
+
+	for _, format := range []legends.Format{legends.NDJSON, legends.CSV} {
+		fail = true
+		output := filepath.Join(dir, "2020-05."+string(format))
+
+		cp, err := crawler.Crawl("2020-05", output, format)
+		if err == nil || cp.Rows != 2 || cp.Done {
+			t.Fatalf("%s: expected the crawl to stop at the second page: %+v %v", format, cp, err)
+		}
+
+		// garbage from a page that was not checkpointed is discarded on resume
+		f, _ := os.OpenFile(output, os.O_APPEND|os.O_WRONLY, 0644)
+		f.WriteString("partial")
+		f.Close()
+
+		cp, err = crawler.Crawl("2020-05", output, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cp.Done || cp.Rows != len(tags) {
+			t.Errorf("%s: unexpected checkpoint: %+v", format, cp)
+		}
+
+		var ranks []int
+		var names []string
+		err = legends.ReadRankings(output, func(r *legends.Ranking) error {
+			ranks = append(ranks, r.Rank)
+			names = append(names, r.Name)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(ranks) != "[1 2 3 4 5]" {
+			t.Errorf("%s: unexpected ranks: %v", format, ranks)
+		}
+		if names[0] != "=player, 0" {
+			t.Errorf("%s: Wanted: =player, 0\tGot: %s", format, names[0])
+		}
+	}
+
+	// a rate limited page is retried while a missing season is not
+	crawler.Retries, crawler.Backoff = 3, time.Millisecond
+	fail, requests = true, 0
+	if _, err := crawler.Crawl("2020-05", filepath.Join(dir, "retried.csv"), legends.CSV); err != nil {
+		t.Errorf("expected the rate limited page to be retried: %v", err)
+	}
+	if requests != 4 {
+		t.Errorf("Wanted: 4 requests\tGot: %d", requests)
+	}
+	requests = 0
+	if _, err := crawler.Crawl("2020-04", filepath.Join(dir, "2020-04.csv"), legends.CSV); err == nil || requests != 1 {
+		t.Errorf("expected a missing season to fail without retrying, %d requests: %v", requests, err)
+	}
+
+	// names that start like a formula are escaped for spreadsheets
+	written, err := ioutil.ReadFile(filepath.Join(dir, "2020-05.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(written), `"'=player, 0"`) {
+		t.Errorf("expected the name to be escaped: %s", written)
+	}
+
+	found, err := legends.FindPlayer("p9", filepath.Join(dir, "2020-05.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Rank != 3 || found[0].Name != "=player, 2" || found[0].ClanTag != "#2PP" {
+		t.Errorf("unexpected rankings: %+v", found)
+	}
+}
@@ -0,0 +1,89 @@
+package legends_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/legends"
+)
+
+func TestDay(t *testing.T) {
+	day := legends.Day(time.Date(2020, 5, 10, 4, 59, 0, 0, time.UTC))
+	if want := time.Date(2020, 5, 9, 5, 0, 0, 0, time.UTC); !day.Equal(want) {
+		t.Errorf("Wanted: %s\tGot: %s", want, day)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	tracker := legends.NewTracker(nil)
+	player := func(trophies, attackWins, defenceWins int) *goclash.Player {
+		return &goclash.Player{Tag: "#P2", Name: "legend", Trophies: trophies, AttackWins: attackWins,
+			DefenceWins: defenceWins}
+	}
+
+	start := time.Date(2020, 5, 10, 4, 0, 0, 0, time.UTC)
+	for i, snapshot := range []struct {
+		player *goclash.Player
+		want   int
+	}{
+		{player(5000, 10, 2), 0},
+		{player(5038, 11, 2), 1},
+		// two attacks between polls
+		{player(5108, 13, 2), 2},
+		// a lost defence and a held defence
+		{player(5080, 13, 3), 2},
+		// after the reset at 5:00
+		{player(5070, 14, 3), 1},
+	} {
+		events := tracker.Record(snapshot.player, start.Add(time.Duration(i)*15*time.Minute))
+		if len(events) != snapshot.want {
+			t.Errorf("poll %d: wanted %d events but got %+v", i, snapshot.want, events)
+		}
+	}
+
+	logs := tracker.Logs("#P2")
+	if len(logs) != 2 {
+		t.Fatalf("expected two legend days but got %d", len(logs))
+	}
+	first := logs[0]
+	if len(first.Attacks) != 3 || first.Gained() != 108 || first.Lost() != 28 || first.Net() != 80 {
+		t.Errorf("unexpected first day: %+v", first)
+	}
+	if first.Attacks[1].Exact || first.Attacks[1].Trophies != 35 {
+		t.Errorf("expected the combined attacks to be split: %+v", first.Attacks[1:])
+	}
+	if second := logs[1]; second.StartTrophies != 5080 || len(second.Mixed) != 1 || second.Net() != -10 {
+		t.Errorf("unexpected second day: %+v", second)
+	}
+}
+
+func TestSeasonReset(t *testing.T) {
+	tracker := legends.NewTracker(nil)
+	player := func(trophies, attackWins int) *goclash.Player {
+		return &goclash.Player{Tag: "#P2", Name: "legend", Trophies: trophies, AttackWins: attackWins}
+	}
+
+	// the May 2020 season ended at 05:00 on the 25th
+	end := goclash.SeasonEnd(time.Date(2020, 5, 20, 0, 0, 0, 0, time.UTC))
+	tracker.Record(player(5400, 30), end.Add(-10*time.Minute))
+
+	events := tracker.Record(player(5040, 1), end.Add(10*time.Minute))
+	if len(events) != 1 || events[0].Kind != legends.SeasonReset || events[0].Trophies != -360 {
+		t.Fatalf("expected a single season reset but got %+v", events)
+	}
+	if events := tracker.Record(player(5075, 2), end.Add(30*time.Minute)); len(events) != 1 ||
+		events[0].Kind != legends.Attack || events[0].Trophies != 35 {
+		t.Errorf("expected an attack after the reset but got %+v", events)
+	}
+
+	logs := tracker.Logs("#P2")
+	if len(logs) != 2 {
+		t.Fatalf("expected two legend days but got %d", len(logs))
+	}
+	day := logs[1]
+	if day.SeasonReset == nil || day.StartTrophies != 5040 || day.Gained() != 35 || day.Lost() != 0 ||
+		day.Net() != 35 {
+		t.Errorf("unexpected day after the reset: %+v", day)
+	}
+}
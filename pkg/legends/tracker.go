@@ -0,0 +1,281 @@
+package legends
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+)
+
+const (
+	// DayResetHour is the hour in UTC a new legend league day starts
+	DayResetHour = 5
+	// MaxTrophiesPerAttack is the most trophies a single legend league attack or
+	// defence can win or lose
+	MaxTrophiesPerAttack = 40
+)
+
+// Day will get the start of the legend league day running at t
+func Day(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), DayResetHour, 0, 0, 0, time.UTC)
+	if t.Before(day) {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
+// EventKind is the kind of battle inferred from a change in a player
+type EventKind string
+
+// Kinds of inferred battles
+const (
+	Attack  EventKind = "attack"
+	Defence EventKind = "defence"
+	// Mixed is used when attacks and defences happened between two polls and the
+	// trophies of each can not be told apart
+	Mixed EventKind = "mixed"
+	// SeasonReset is used when a trophy season ended between two polls. The
+	// trophies of any battles around the reset can not be told apart from it
+	SeasonReset EventKind = "seasonReset"
+)
+
+// Event is a battle inferred from the change in a player between two polls
+type Event struct {
+	Tag  string
+	Kind EventKind
+	// Time is when the change was seen
+	Time time.Time
+	// Trophies is the change in trophies caused by the battle
+	Trophies int
+	// Exact is false when several battles happened between two polls and the
+	// trophies were split evenly between them
+	Exact bool
+}
+
+// Events will infer the battles a player had between a snapshot taken at since and
+// one taken at t from the change in trophies, attack wins and defence wins. If a
+// trophy season ended in between only a single SeasonReset event is returned
+func Events(prev, cur *goclash.Player, since, t time.Time) []Event {
+	delta := cur.Trophies - prev.Trophies
+	if !goclash.SeasonEnd(since).Equal(goclash.SeasonEnd(t)) {
+		return []Event{{Tag: cur.Tag, Kind: SeasonReset, Time: t, Trophies: delta}}
+	}
+
+	attackWins := cur.AttackWins - prev.AttackWins
+	defenceWins := cur.DefenceWins - prev.DefenceWins
+	if attackWins < 0 || defenceWins < 0 {
+		// the counters were reset with the season so only the trophies can be used
+		attackWins, defenceWins = 0, 0
+	}
+
+	var events []Event
+	switch {
+	case delta < 0 && attackWins > 0:
+		events = append(events, Event{Tag: cur.Tag, Kind: Mixed, Time: t, Trophies: delta})
+	case delta < 0:
+		events = split(cur.Tag, Defence, t, delta, 0)
+	case delta > 0 || attackWins > 0:
+		events = split(cur.Tag, Attack, t, delta, attackWins)
+	}
+
+	// defences that were held do not lose any trophies
+	for i := 0; i < defenceWins; i++ {
+		events = append(events, Event{Tag: cur.Tag, Kind: Defence, Time: t, Exact: true})
+	}
+
+	return events
+}
+
+// split will spread a change in trophies over the fewest battles that could have
+// caused it, or count battles if more are known to have happened
+func split(tag string, kind EventKind, t time.Time, delta, count int) []Event {
+	trophies := delta
+	if trophies < 0 {
+		trophies = -trophies
+	}
+	if n := (trophies + MaxTrophiesPerAttack - 1) / MaxTrophiesPerAttack; n > count {
+		count = n
+	}
+	if count == 0 {
+		return nil
+	}
+
+	events := make([]Event, 0, count)
+	for i := 0; i < count; i++ {
+		share := trophies / count
+		if i < trophies%count {
+			share++
+		}
+		if delta < 0 {
+			share = -share
+		}
+		events = append(events, Event{Tag: tag, Kind: kind, Time: t, Trophies: share, Exact: count == 1})
+	}
+	return events
+}
+
+// DayLog holds the battles of a player during a single legend league day
+type DayLog struct {
+	Tag  string
+	Name string
+	// Day is the start of the legend league day
+	Day           time.Time
+	StartTrophies int
+	EndTrophies   int
+	Attacks       []Event
+	Defences      []Event
+	Mixed         []Event
+	// SeasonReset is set if the trophy season ended during the day. StartTrophies
+	// is then the trophies the player had once the season reset
+	SeasonReset *Event
+}
+
+// Gained is the amount of trophies won from attacks
+func (dl *DayLog) Gained() int {
+	return sum(dl.Attacks)
+}
+
+// Lost is the amount of trophies lost from defences
+func (dl *DayLog) Lost() int {
+	return -sum(dl.Defences)
+}
+
+// Net is the change in trophies over the day
+func (dl *DayLog) Net() int {
+	return dl.EndTrophies - dl.StartTrophies
+}
+
+func sum(events []Event) int {
+	total := 0
+	for _, e := range events {
+		total += e.Trophies
+	}
+	return total
+}
+
+// add will add events to the log
+func (dl *DayLog) add(events []Event) {
+	for _, e := range events {
+		switch e.Kind {
+		case Attack:
+			dl.Attacks = append(dl.Attacks, e)
+		case Defence:
+			dl.Defences = append(dl.Defences, e)
+		case SeasonReset:
+			reset := e
+			dl.SeasonReset = &reset
+			dl.StartTrophies = dl.EndTrophies
+		default:
+			dl.Mixed = append(dl.Mixed, e)
+		}
+	}
+}
+
+// Tracker polls players in legend league and builds a log of their battles for
+// each legend league day. Polls should be frequent, e.g. every minute, as battles
+// between two polls can only be told apart by their trophies
+type Tracker struct {
+	client *goclash.Client
+	tags   []string
+
+	mu   sync.Mutex
+	last map[string]snapshot
+	logs map[string][]*DayLog
+}
+
+// snapshot is a player and when it was seen
+type snapshot struct {
+	player *goclash.Player
+	time   time.Time
+}
+
+// NewTracker will create a new Tracker for the players with the given tags
+func NewTracker(client *goclash.Client, tags ...string) *Tracker {
+	return &Tracker{
+		client: client,
+		tags:   tags,
+		last:   make(map[string]snapshot, len(tags)),
+		logs:   make(map[string][]*DayLog, len(tags)),
+	}
+}
+
+// Poll will get every player and return the battles inferred since the last poll.
+// Players that could not be fetched are skipped and reported in the error
+func (t *Tracker) Poll() ([]Event, error) {
+	now := time.Now().UTC()
+
+	var (
+		events []Event
+		failed []string
+	)
+	for _, tag := range t.tags {
+		player, err := t.client.Player.Get(tag)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", tag, err.Error()))
+			continue
+		}
+		events = append(events, t.Record(player, now)...)
+	}
+
+	if len(failed) > 0 {
+		return events, fmt.Errorf("could not get players: %s", strings.Join(failed, ", "))
+	}
+	return events, nil
+}
+
+// Record will add a snapshot of a player seen at now to the tracker and return
+// the battles inferred since the previous snapshot
+func (t *Tracker) Record(player *goclash.Player, now time.Time) []Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prev, ok := t.last[player.Tag]
+	t.last[player.Tag] = snapshot{player: player, time: now}
+
+	dayLog := t.day(player, Day(now))
+	dayLog.EndTrophies = player.Trophies
+	if !ok {
+		return nil
+	}
+
+	events := Events(prev.player, player, prev.time, now)
+	dayLog.add(events)
+	return events
+}
+
+// day will get the log of a player for a day, starting a new one if needed
+func (t *Tracker) day(player *goclash.Player, day time.Time) *DayLog {
+	logs := t.logs[player.Tag]
+	if len(logs) > 0 && logs[len(logs)-1].Day.Equal(day) {
+		return logs[len(logs)-1]
+	}
+
+	start := player.Trophies
+	if len(logs) > 0 {
+		// the day started with the trophies the player had at the end of the last day
+		start = logs[len(logs)-1].EndTrophies
+	}
+
+	dayLog := &DayLog{Tag: player.Tag, Name: player.Name, Day: day, StartTrophies: start}
+	t.logs[player.Tag] = append(logs, dayLog)
+	return dayLog
+}
+
+// Logs will get every day log of a player, oldest first
+func (t *Tracker) Logs(tag string) []*DayLog {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*DayLog{}, t.logs[tag]...)
+}
+
+// Today will get the log of a player for the current legend league day
+func (t *Tracker) Today(tag string) (*DayLog, bool) {
+	logs := t.Logs(tag)
+	if len(logs) == 0 || !logs[len(logs)-1].Day.Equal(Day(time.Now())) {
+		return nil, false
+	}
+	return logs[len(logs)-1], true
+}
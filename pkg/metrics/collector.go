@@ -0,0 +1,183 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultCacheTTL is how long metrics are cached between scrapes by default. The
+// Clash of Clans API only updates most values every few minutes
+const DefaultCacheTTL = 5 * time.Minute
+
+func newGauge(name, help string, labelNames ...string) *prometheus.Desc {
+	return prometheus.NewDesc("clash_"+name, help, labelNames, nil)
+}
+
+// Names are only exported by the info metrics so a renamed clan or player does
+// not start a new time series for every other metric
+var (
+	clanInfo         = newGauge("clan_info", "Name of a clan, always 1.", "clan", "name")
+	clanPoints       = newGauge("clan_points", "Clan trophy points.", "clan")
+	clanVersusPoints = newGauge("clan_versus_points", "Clan versus trophy points.", "clan")
+	clanLevel        = newGauge("clan_level", "Clan level.", "clan")
+	clanMembers      = newGauge("clan_members", "Number of members in the clan.", "clan")
+	clanWarWins      = newGauge("clan_war_wins", "Number of wars the clan has won.", "clan")
+	clanWarStreak    = newGauge("clan_war_win_streak", "Current war win streak of the clan.", "clan")
+
+	memberInfo      = newGauge("member_info", "Name of a clan member, always 1.", "clan", "member", "name")
+	memberTrophies  = newGauge("member_trophies", "Trophies of a clan member.", "clan", "member")
+	memberDonations = newGauge("member_donations", "Troops donated by a clan member this season.",
+		"clan", "member")
+	memberReceived = newGauge("member_donations_received",
+		"Troops received by a clan member this season.", "clan", "member")
+
+	warStars         = newGauge("war_stars", "Stars earned in the current war.", "clan", "opponent", "state")
+	warOpponentStars = newGauge("war_opponent_stars", "Stars earned by the opponent in the current war.",
+		"clan", "opponent", "state")
+	warDestruction = newGauge("war_destruction_percentage", "Destruction percentage in the current war.",
+		"clan", "opponent", "state")
+	warAttacks = newGauge("war_attacks", "Attacks used in the current war.", "clan", "opponent", "state")
+
+	playerInfo         = newGauge("player_info", "Name of a player, always 1.", "player", "name")
+	playerTrophies     = newGauge("player_trophies", "Trophies of a player.", "player")
+	playerBestTrophies = newGauge("player_best_trophies", "Best trophies of a player.", "player")
+	playerWarStars     = newGauge("player_war_stars", "Total war stars of a player.", "player")
+	playerExpLevel     = newGauge("player_exp_level", "Experience level of a player.", "player")
+	playerTownhall     = newGauge("player_townhall_level", "Town hall level of a player.", "player")
+	playerAttackWins   = newGauge("player_attack_wins", "Attacks won this season.", "player")
+	playerDefenceWins  = newGauge("player_defense_wins", "Defenses won this season.", "player")
+	playerDonations    = newGauge("player_donations", "Troops donated this season.", "player")
+
+	scrapeSuccess = newGauge("scrape_success", "Whether the last fetch of a target succeeded.",
+		"target", "kind")
+)
+
+var descs = []*prometheus.Desc{
+	clanInfo, clanPoints, clanVersusPoints, clanLevel, clanMembers, clanWarWins, clanWarStreak,
+	memberInfo, memberTrophies, memberDonations, memberReceived,
+	warStars, warOpponentStars, warDestruction, warAttacks,
+	playerInfo, playerTrophies, playerBestTrophies, playerWarStars, playerExpLevel, playerTownhall,
+	playerAttackWins, playerDefenceWins, playerDonations,
+	scrapeSuccess,
+}
+
+// Collector gathers clan, current war and player metrics for a set of tags. It
+// implements prometheus.Collector so it can be registered with a registry
+type Collector struct {
+	client     *goclash.Client
+	clanTags   []string
+	playerTags []string
+	ttl        time.Duration
+
+	mu      sync.Mutex
+	cached  []prometheus.Metric
+	fetched time.Time
+}
+
+// NewCollector will create a new Collector. Metrics are fetched at most once per
+// ttl, scrapes in between are served from the cache
+func NewCollector(client *goclash.Client, clanTags, playerTags []string, ttl time.Duration) *Collector {
+	return &Collector{
+		client:     client,
+		clanTags:   clanTags,
+		playerTags: playerTags,
+		ttl:        ttl,
+	}
+}
+
+// Describe will send the description of every metric the collector produces to ch
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range descs {
+		ch <- desc
+	}
+}
+
+// Collect will send every metric to ch, fetching them from the API if the cache
+// has expired
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, metric := range c.metrics() {
+		ch <- metric
+	}
+}
+
+func (c *Collector) metrics() []prometheus.Metric {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached != nil && time.Since(c.fetched) < c.ttl {
+		return c.cached
+	}
+
+	c.cached = c.fetch()
+	c.fetched = time.Now()
+	return c.cached
+}
+
+func (c *Collector) fetch() []prometheus.Metric {
+	var metrics []prometheus.Metric
+	add := func(desc *prometheus.Desc, value float64, labelValues ...string) {
+		metrics = append(metrics, prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value,
+			labelValues...))
+	}
+	success := func(target, kind string, err error) {
+		if err != nil {
+			add(scrapeSuccess, 0, target, kind)
+			return
+		}
+		add(scrapeSuccess, 1, target, kind)
+	}
+
+	for _, tag := range c.clanTags {
+		clan, err := c.client.Clan.Get(tag)
+		success(tag, "clan", err)
+		if err == nil {
+			add(clanInfo, 1, clan.Tag, clan.Name)
+			add(clanPoints, float64(clan.Points), clan.Tag)
+			add(clanVersusPoints, float64(clan.VersusPoints), clan.Tag)
+			add(clanLevel, float64(clan.Level), clan.Tag)
+			add(clanMembers, float64(clan.MemberCount), clan.Tag)
+			add(clanWarWins, float64(clan.WarWins), clan.Tag)
+			add(clanWarStreak, float64(clan.WarWinStreak), clan.Tag)
+
+			for _, m := range clan.Members {
+				add(memberInfo, 1, clan.Tag, m.Tag, m.Name)
+				add(memberTrophies, float64(m.Trophies), clan.Tag, m.Tag)
+				add(memberDonations, float64(m.Donations), clan.Tag, m.Tag)
+				add(memberReceived, float64(m.DonationsReceived), clan.Tag, m.Tag)
+			}
+		}
+
+		// the current war is not available when the war log is private
+		war, err := c.client.Clan.GetCurrentWar(tag)
+		success(tag, "war", err)
+		if err == nil && war.State != "notInWar" {
+			labels := []string{war.Clan.Tag, war.OpponentClan.Tag, war.State}
+			add(warStars, float64(war.Clan.Stars), labels...)
+			add(warOpponentStars, float64(war.OpponentClan.Stars), labels...)
+			add(warDestruction, float64(war.Clan.DestructionPercentage), labels...)
+			add(warAttacks, float64(war.Clan.Attacks), labels...)
+		}
+	}
+
+	for _, tag := range c.playerTags {
+		player, err := c.client.Player.Get(tag)
+		success(tag, "player", err)
+		if err != nil {
+			continue
+		}
+		add(playerInfo, 1, player.Tag, player.Name)
+		add(playerTrophies, float64(player.Trophies), player.Tag)
+		add(playerBestTrophies, float64(player.BestTrophies), player.Tag)
+		add(playerWarStars, float64(player.WarStars), player.Tag)
+		add(playerExpLevel, float64(player.ExpLevel), player.Tag)
+		add(playerTownhall, float64(player.TownhallLevel), player.Tag)
+		add(playerAttackWins, float64(player.AttackWins), player.Tag)
+		add(playerDefenceWins, float64(player.DefenceWins), player.Tag)
+		add(playerDonations, float64(player.Donations), player.Tag)
+	}
+
+	return metrics
+}
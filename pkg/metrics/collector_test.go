@@ -0,0 +1,81 @@
+package metrics_test
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var responses = map[string]string{
+	"/clans/#2PP": `{"tag": "#2PP", "name": "The \"Best\"", "clanPoints": 41234, "members": 1,
+		"memberList": [{"tag": "#M1", "name": "one", "trophies": 5000, "donations": 300}]}`,
+	"/clans/#2PP/currentwar": `{"state": "inWar", "clan": {"tag": "#2PP", "stars": 12, "attacks": 7},
+		"opponent": {"tag": "#8QU", "stars": 9}}`,
+	"/players/#9LY": `{"tag": "#9LY", "name": "player", "trophies": 5321, "townhallLevel": 14}`,
+}
+
+func TestCollector(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"reason": "notFound"}`))
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client, err := goclash.NewClient("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	client.SetLogger(log.New(ioutil.Discard, "", 0))
+
+	collector := metrics.NewCollector(client, []string{"#2PP"}, []string{"#9LY", "#LLL"}, time.Hour)
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(collector); err != nil {
+		t.Fatal(err)
+	}
+	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	scrape := func() string {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		return rec.Body.String()
+	}
+
+	body := scrape()
+	for _, line := range []string{
+		"# TYPE clash_clan_points gauge",
+		`clash_clan_info{clan="#2PP",name="The \"Best\""} 1`,
+		`clash_clan_points{clan="#2PP"} 41234`,
+		`clash_member_donations{clan="#2PP",member="#M1"} 300`,
+		`clash_war_stars{clan="#2PP",opponent="#8QU",state="inWar"} 12`,
+		`clash_player_info{name="player",player="#9LY"} 1`,
+		`clash_player_trophies{player="#9LY"} 5321`,
+		`clash_scrape_success{kind="player",target="#LLL"} 0`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("metrics are missing %q:\n%s", line, body)
+		}
+	}
+
+	fetched := atomic.LoadInt32(&requests)
+	if scrape() != body || atomic.LoadInt32(&requests) != fetched {
+		t.Error("expected the second scrape to be served from the cache")
+	}
+}
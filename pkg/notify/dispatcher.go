@@ -0,0 +1,232 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const (
+	// DiscordTemplate renders an event as a Discord webhook payload
+	DiscordTemplate = `{"content": {{json .Message}}}`
+	// SlackTemplate renders an event as a Slack incoming webhook payload
+	SlackTemplate = `{"text": {{json .Message}}}`
+
+	// DefaultDedupWindow is how long an event key is remembered by default
+	DefaultDedupWindow = 24 * time.Hour
+)
+
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// Destination is a webhook events are delivered to
+type Destination struct {
+	Name string
+	URL  string
+	// Template renders an Event into the request body. The event is passed as the
+	// template data and a json function is available to quote values
+	Template string
+	// Types limits the events sent to this destination. All events are sent when
+	// Types is empty
+	Types []EventType
+	// Interval is the minimum time between two requests to this destination
+	Interval time.Duration
+	// Retries is how many times a failed delivery is retried
+	Retries int
+	// Backoff is the wait before the first retry, it doubles for every retry
+	Backoff time.Duration
+}
+
+// destination holds the state of a Destination within a dispatcher
+type destination struct {
+	Destination
+	tmpl *template.Template
+	// mu serialises requests so Interval is respected
+	mu   sync.Mutex
+	last time.Time
+}
+
+// Dispatcher delivers events to webhooks, skipping events it has already delivered
+type Dispatcher struct {
+	httpclient   http.Client
+	destinations []*destination
+	window       time.Duration
+
+	mu   sync.Mutex
+	seen map[delivery]time.Time
+}
+
+// delivery identifies an event delivered to a destination
+type delivery struct {
+	dest *destination
+	key  string
+}
+
+// NewDispatcher will create a new Dispatcher for a set of destinations
+func NewDispatcher(destinations ...Destination) (*Dispatcher, error) {
+	d := Dispatcher{
+		httpclient: http.Client{Timeout: 10 * time.Second},
+		window:     DefaultDedupWindow,
+		seen:       make(map[delivery]time.Time),
+	}
+
+	for _, dest := range destinations {
+		tmpl, err := template.New(dest.Name).Funcs(templateFuncs).Parse(dest.Template)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse template for %s: %s", dest.Name, err.Error())
+		}
+		d.destinations = append(d.destinations, &destination{Destination: dest, tmpl: tmpl})
+	}
+
+	return &d, nil
+}
+
+// SetDedupWindow will set how long delivered event keys are remembered for
+func (d *Dispatcher) SetDedupWindow(window time.Duration) {
+	d.window = window
+}
+
+// SetTimeout will set a timeout for webhook requests
+func (d *Dispatcher) SetTimeout(duration time.Duration) {
+	d.httpclient.Timeout = duration
+}
+
+// Dispatch will deliver events to every destination that accepts them. Events
+// with a key that was delivered to a destination within the deduplication window
+// are not sent to it again, so only the destinations that failed are retried on
+// the next dispatch. The first delivery error is returned after every event has
+// been attempted
+func (d *Dispatcher) Dispatch(events ...Event) error {
+	var firstErr error
+	for i := range events {
+		for _, dest := range d.destinations {
+			if !dest.accepts(events[i].Type) || !d.claim(dest, events[i].Key) {
+				continue
+			}
+			if err := d.deliver(dest, &events[i]); err != nil {
+				// allow the event to be retried on the next dispatch
+				d.release(dest, events[i].Key)
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+		}
+	}
+	return firstErr
+}
+
+// claim reports whether an event should be delivered to a destination and marks
+// it as seen
+func (d *Dispatcher) claim(dest *destination, key string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for k, at := range d.seen {
+		if now.Sub(at) > d.window {
+			delete(d.seen, k)
+		}
+	}
+
+	if key == "" {
+		return true
+	}
+	if _, ok := d.seen[delivery{dest, key}]; ok {
+		return false
+	}
+	d.seen[delivery{dest, key}] = now
+	return true
+}
+
+func (d *Dispatcher) release(dest *destination, key string) {
+	d.mu.Lock()
+	delete(d.seen, delivery{dest, key})
+	d.mu.Unlock()
+}
+
+func (dest *destination) accepts(t EventType) bool {
+	if len(dest.Types) == 0 {
+		return true
+	}
+	for _, accepted := range dest.Types {
+		if accepted == t {
+			return true
+		}
+	}
+	return false
+}
+
+// wait blocks until the destination can be sent another request
+func (dest *destination) wait() {
+	if dest.Interval <= 0 {
+		return
+	}
+	if next := dest.last.Add(dest.Interval); time.Now().Before(next) {
+		time.Sleep(time.Until(next))
+	}
+}
+
+func (d *Dispatcher) deliver(dest *destination, event *Event) error {
+	var body bytes.Buffer
+	if err := dest.tmpl.Execute(&body, event); err != nil {
+		return fmt.Errorf("could not render %s payload: %s", dest.Name, err.Error())
+	}
+
+	dest.mu.Lock()
+	defer dest.mu.Unlock()
+
+	backoff := dest.Backoff
+	for attempt := 0; ; attempt++ {
+		dest.wait()
+		retryAfter, err := d.send(dest, body.Bytes())
+		dest.last = time.Now()
+		if err == nil {
+			return nil
+		}
+		if attempt >= dest.Retries {
+			return fmt.Errorf("could not deliver %s to %s: %s", event.Type, dest.Name, err.Error())
+		}
+
+		wait := backoff
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		time.Sleep(wait)
+		backoff *= 2
+	}
+}
+
+// send will post a payload to the destination. If the destination asked us to
+// slow down the requested wait is returned with the error
+func (d *Dispatcher) send(dest *destination, payload []byte) (time.Duration, error) {
+	req, err := http.NewRequest("POST", dest.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("could not create request: %s", err.Error())
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.httpclient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("could not do request: %s", err.Error())
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < http.StatusBadRequest {
+		return 0, nil
+	}
+
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+	return retryAfter, fmt.Errorf("webhook responded with %s", resp.Status)
+}
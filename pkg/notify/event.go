@@ -0,0 +1,207 @@
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+)
+
+// EventType is the kind of change an event describes
+type EventType string
+
+// Event types produced when comparing snapshots
+const (
+	WarPreparation    EventType = "warPreparation"
+	WarStarted        EventType = "warStarted"
+	WarAttack         EventType = "warAttack"
+	WarEnded          EventType = "warEnded"
+	MemberJoined      EventType = "memberJoined"
+	MemberLeft        EventType = "memberLeft"
+	MemberRoleChanged EventType = "memberRoleChanged"
+)
+
+// Event is a change in a clan or its current war
+type Event struct {
+	Type EventType
+	// Key uniquely identifies the event so it is only delivered once
+	Key      string
+	ClanTag  string
+	ClanName string
+
+	War      *goclash.War
+	Attack   *goclash.Attack
+	Attacker *goclash.WarMember
+	Defender *goclash.WarMember
+
+	Member  *goclash.Member
+	OldRole string
+}
+
+// Message is a short human readable description of the event
+func (e *Event) Message() string {
+	switch e.Type {
+	case WarPreparation:
+		return fmt.Sprintf("%s found a war against %s (%dv%d), battle day starts %s",
+			e.War.Clan.Name, e.War.OpponentClan.Name, e.War.TeamSize, e.War.TeamSize,
+			e.War.StartTime.UTC().Format("Jan 2 15:04 MST"))
+	case WarStarted:
+		return fmt.Sprintf("Battle day against %s has started", e.War.OpponentClan.Name)
+	case WarAttack:
+		return fmt.Sprintf("%s attacked %s: %s %.0f%%", e.Attacker.Name, e.Defender.Name,
+			strings.Repeat("★", e.Attack.Stars)+strings.Repeat("☆", 3-e.Attack.Stars),
+			e.Attack.DestructionPercentage)
+	case WarEnded:
+		return fmt.Sprintf("War against %s ended %d-%d (%.2f%% to %.2f%%)", e.War.OpponentClan.Name,
+			e.War.Clan.Stars, e.War.OpponentClan.Stars, e.War.Clan.DestructionPercentage,
+			e.War.OpponentClan.DestructionPercentage)
+	case MemberJoined:
+		return fmt.Sprintf("%s (%s) joined %s", e.Member.Name, e.Member.Tag, e.ClanName)
+	case MemberLeft:
+		return fmt.Sprintf("%s (%s) left %s", e.Member.Name, e.Member.Tag, e.ClanName)
+	case MemberRoleChanged:
+		return fmt.Sprintf("%s is now %s (was %s)", e.Member.Name, e.Member.Role, e.OldRole)
+	}
+	return string(e.Type)
+}
+
+// warKey identifies a war by the two clans and when preparation started
+func warKey(w *goclash.War) string {
+	return fmt.Sprintf("%s/%s/%d", w.Clan.Tag, w.OpponentClan.Tag, w.PreparationStartTime.Unix())
+}
+
+// WarEvents will compare two snapshots of a clans current war and return the
+// events that happened in between. prev may be nil for the first snapshot
+func WarEvents(prev, cur *goclash.War) []Event {
+	if cur == nil || cur.State == "notInWar" || cur.OpponentClan.Tag == "" {
+		return nil
+	}
+
+	key := warKey(cur)
+	if prev != nil && warKey(prev) != key {
+		// a new war was found, every attack in the previous snapshot is stale
+		prev = nil
+	}
+
+	var events []Event
+	newEvent := func(t EventType, suffix string) Event {
+		return Event{
+			Type:     t,
+			Key:      key + "/" + string(t) + suffix,
+			ClanTag:  cur.Clan.Tag,
+			ClanName: cur.Clan.Name,
+			War:      cur,
+		}
+	}
+
+	stateChanged := prev == nil || prev.State != cur.State
+	if stateChanged {
+		switch cur.State {
+		case "preparation":
+			events = append(events, newEvent(WarPreparation, ""))
+		case "inWar":
+			events = append(events, newEvent(WarStarted, ""))
+		}
+	}
+
+	seen := make(map[int]bool)
+	if prev != nil {
+		for _, team := range [][]goclash.WarMember{prev.Clan.Team, prev.OpponentClan.Team} {
+			for _, member := range team {
+				for _, attack := range member.Attacks {
+					seen[attack.Order] = true
+				}
+			}
+		}
+	}
+
+	var attacks []Event
+	appendAttacks := func(attackers, defenders []goclash.WarMember) {
+		byTag := make(map[string]*goclash.WarMember, len(defenders))
+		for i := range defenders {
+			byTag[defenders[i].Tag] = &defenders[i]
+		}
+		for i := range attackers {
+			for j := range attackers[i].Attacks {
+				attack := &attackers[i].Attacks[j]
+				if seen[attack.Order] {
+					continue
+				}
+				defender, ok := byTag[attack.DefenderTag]
+				if !ok {
+					defender = &goclash.WarMember{Tag: attack.DefenderTag, Name: attack.DefenderTag}
+				}
+				event := newEvent(WarAttack, fmt.Sprintf("/%d", attack.Order))
+				event.Attack = attack
+				event.Attacker = &attackers[i]
+				event.Defender = defender
+				attacks = append(attacks, event)
+			}
+		}
+	}
+	appendAttacks(cur.Clan.Team, cur.OpponentClan.Team)
+	appendAttacks(cur.OpponentClan.Team, cur.Clan.Team)
+	sort.SliceStable(attacks, func(i, j int) bool {
+		return attacks[i].Attack.Order < attacks[j].Attack.Order
+	})
+	events = append(events, attacks...)
+
+	if stateChanged && cur.State == "warEnded" {
+		events = append(events, newEvent(WarEnded, ""))
+	}
+
+	return events
+}
+
+// ClanEvents will compare two snapshots of a clan and return the member changes
+// between them, where at is when cur was taken. No events are returned for the
+// first snapshot. Member event keys include at, so a member leaving, rejoining and
+// leaving again within the dispatchers deduplication window is announced each time
+func ClanEvents(prev, cur *goclash.Clan, at time.Time) []Event {
+	if prev == nil || cur == nil {
+		return nil
+	}
+
+	newEvent := func(t EventType, member *goclash.Member) Event {
+		return Event{
+			Type:     t,
+			Key:      fmt.Sprintf("%s/%s/%s/%d", cur.Tag, t, member.Tag, at.Unix()),
+			ClanTag:  cur.Tag,
+			ClanName: cur.Name,
+			Member:   member,
+		}
+	}
+
+	before := make(map[string]*goclash.Member, len(prev.Members))
+	for i := range prev.Members {
+		before[prev.Members[i].Tag] = &prev.Members[i]
+	}
+
+	var events []Event
+	for i := range cur.Members {
+		member := &cur.Members[i]
+		old, ok := before[member.Tag]
+		if !ok {
+			events = append(events, newEvent(MemberJoined, member))
+			continue
+		}
+		delete(before, member.Tag)
+
+		if old.Role != member.Role {
+			event := newEvent(MemberRoleChanged, member)
+			event.Key += "/" + old.Role + "/" + member.Role
+			event.OldRole = old.Role
+			events = append(events, event)
+		}
+	}
+
+	for i := range prev.Members {
+		if member, ok := before[prev.Members[i].Tag]; ok {
+			events = append(events, newEvent(MemberLeft, member))
+		}
+	}
+
+	return events
+}
@@ -0,0 +1,285 @@
+package notify_test
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/notify"
+)
+
+func snapshot(state string, attacks ...goclash.Attack) *goclash.War {
+	return &goclash.War{
+		State: state,
+		Clan: goclash.WarClan{Tag: "#US", Name: "Us", Team: []goclash.WarMember{
+			{Tag: "#A", Name: "alpha", Attacks: attacks},
+		}},
+		OpponentClan: goclash.WarClan{Tag: "#THEM", Name: "Them", Team: []goclash.WarMember{
+			{Tag: "#D", Name: "delta"},
+		}},
+	}
+}
+
+func TestWarEvents(t *testing.T) {
+	first := goclash.Attack{Order: 1, AttackerTag: "#A", DefenderTag: "#D", Stars: 2, DestructionPercentage: 75}
+	second := goclash.Attack{Order: 2, AttackerTag: "#A", DefenderTag: "#D", Stars: 3, DestructionPercentage: 100}
+
+	events := notify.WarEvents(snapshot("preparation"), snapshot("inWar", first))
+	if len(events) != 2 || events[0].Type != notify.WarStarted || events[1].Type != notify.WarAttack {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if msg := events[1].Message(); msg != "alpha attacked delta: ★★☆ 75%" {
+		t.Errorf("unexpected message: %s", msg)
+	}
+
+	events = notify.WarEvents(snapshot("inWar", first), snapshot("warEnded", first, second))
+	if len(events) != 2 || events[0].Attack.Order != 2 || events[1].Type != notify.WarEnded {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+}
+
+func TestClanEvents(t *testing.T) {
+	prev := &goclash.Clan{Tag: "#US", Members: []goclash.Member{
+		{Tag: "#A", Name: "alpha", Role: "member"}, {Tag: "#B", Name: "bravo", Role: "admin"},
+	}}
+	cur := &goclash.Clan{Tag: "#US", Members: []goclash.Member{
+		{Tag: "#A", Name: "alpha", Role: "admin"}, {Tag: "#C", Name: "charlie", Role: "member"},
+	}}
+
+	events := notify.ClanEvents(prev, cur, time.Now())
+	want := []notify.EventType{notify.MemberRoleChanged, notify.MemberJoined, notify.MemberLeft}
+	if len(events) != len(want) {
+		t.Fatalf("Wanted: %v\tGot: %+v", want, events)
+	}
+	for i := range want {
+		if events[i].Type != want[i] {
+			t.Errorf("Wanted: %s\tGot: %s", want[i], events[i].Type)
+		}
+	}
+}
+
+func TestClanEventsRepeated(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		payloads []string
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		b, _ := ioutil.ReadAll(r.Body)
+		payloads = append(payloads, string(b))
+	}))
+	defer receiver.Close()
+
+	dispatcher, err := notify.NewDispatcher(notify.Destination{
+		Name:     "discord",
+		URL:      receiver.URL,
+		Template: notify.DiscordTemplate,
+		Types:    []notify.EventType{notify.MemberLeft},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	with := &goclash.Clan{Tag: "#US", Name: "Us", Members: []goclash.Member{{Tag: "#A", Name: "alpha"}}}
+	without := &goclash.Clan{Tag: "#US", Name: "Us"}
+	now := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	// alpha leaves, rejoins and leaves again within the deduplication window
+	for _, at := range []time.Time{now, now.Add(10 * time.Minute)} {
+		if err = dispatcher.Dispatch(notify.ClanEvents(with, without, at)...); err != nil {
+			t.Fatal(err)
+		}
+		if err = dispatcher.Dispatch(notify.ClanEvents(without, with, at.Add(5*time.Minute))...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the same snapshots are only announced once
+	if err = dispatcher.Dispatch(notify.ClanEvents(with, without, now)...); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(payloads) != 2 {
+		t.Errorf("Wanted: 2 deliveries of the leave\tGot: %q", payloads)
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		payloads []string
+		failures = 1
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		payloads = append(payloads, string(b))
+	}))
+	defer receiver.Close()
+
+	dispatcher, err := notify.NewDispatcher(notify.Destination{
+		Name:     "discord",
+		URL:      receiver.URL,
+		Template: notify.DiscordTemplate,
+		Types:    []notify.EventType{notify.WarStarted},
+		Interval: time.Millisecond,
+		Retries:  2,
+		Backoff:  time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := notify.WarEvents(nil, snapshot("inWar"))
+	if err = dispatcher.Dispatch(events...); err != nil {
+		t.Fatal(err)
+	}
+	// the same event should not be delivered twice
+	if err = dispatcher.Dispatch(events...); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(payloads) != 1 || payloads[0] != `{"content": "Battle day against Them has started"}` {
+		t.Errorf("unexpected payloads: %q", payloads)
+	}
+}
+
+func TestDispatcherRetriesFailedDestinations(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		healthy int
+		broken  int
+		fixed   bool
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/healthy" {
+			healthy++
+			return
+		}
+		broken++
+		if !fixed {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer receiver.Close()
+
+	dispatcher, err := notify.NewDispatcher(
+		notify.Destination{Name: "healthy", URL: receiver.URL + "/healthy", Template: notify.SlackTemplate},
+		notify.Destination{Name: "broken", URL: receiver.URL + "/broken", Template: notify.SlackTemplate},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := notify.WarEvents(nil, snapshot("inWar"))
+	if err = dispatcher.Dispatch(events...); err == nil {
+		t.Fatal("expected the broken destination to fail")
+	}
+
+	mu.Lock()
+	fixed = true
+	mu.Unlock()
+	if err = dispatcher.Dispatch(events...); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if healthy != 1 || broken != 2 {
+		t.Errorf("Wanted: 1 healthy and 2 broken deliveries\tGot: %d and %d", healthy, broken)
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		members    = `[{"tag": "#A", "name": "alpha"}]`
+		private    = true
+		currentWar = `{"state": "inWar", "clan": {"tag": "#UV", "members": [{"tag": "#A", "name": "alpha",
+			"attacks": [{"order": 1, "attackerTag": "#A", "defenderTag": "#D", "stars": 2}]}]},
+			"opponent": {"tag": "#C", "members": [{"tag": "#D", "name": "delta"}]}}`
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/clans/#UV":
+			w.Write([]byte(`{"tag": "#UV", "memberList": ` + members + `}`))
+		case "/clans/#UV/currentwar":
+			if private {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"reason": "accessDenied"}`))
+				return
+			}
+			w.Write([]byte(currentWar))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"reason": "notFound"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := goclash.NewClient("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	client.SetLogger(log.New(ioutil.Discard, "", 0))
+
+	watcher := notify.NewWatcher(client, "#UV")
+	events, err := watcher.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 || watcher.WarError == nil {
+		t.Fatalf("the first poll should only record the clan, got %+v", events)
+	}
+
+	// member events are still reported while the war log is private
+	mu.Lock()
+	members = `[{"tag": "#A", "name": "alpha"}, {"tag": "#B", "name": "bravo"}]`
+	mu.Unlock()
+	if events, err = watcher.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Type != notify.MemberJoined {
+		t.Fatalf("Wanted: bravo to join	Got: %+v", events)
+	}
+
+	// the war is only recorded the first time it can be seen
+	mu.Lock()
+	private = false
+	mu.Unlock()
+	if events, err = watcher.Poll(); err != nil || len(events) != 0 || watcher.WarError != nil {
+		t.Fatalf("the first war poll should only record the war, got %+v, %v", events, err)
+	}
+
+	mu.Lock()
+	currentWar = strings.Replace(currentWar, `"inWar"`, `"warEnded"`, 1)
+	mu.Unlock()
+	if events, err = watcher.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Type != notify.WarEnded {
+		t.Errorf("Wanted: only the war to end\tGot: %+v", events)
+	}
+}
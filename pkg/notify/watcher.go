@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+)
+
+// Watcher keeps the last snapshots of a clan and its current war so changes can
+// be turned into events
+type Watcher struct {
+	client  *goclash.Client
+	clanTag string
+
+	clan *goclash.Clan
+	war  *goclash.War
+
+	// WarError holds the error of the last current war fetch, which fails for
+	// clans with a private war log. Member events are still reported when it does
+	WarError error
+}
+
+// NewWatcher will create a new Watcher for a clan
+func NewWatcher(client *goclash.Client, clanTag string) *Watcher {
+	return &Watcher{
+		client:  client,
+		clanTag: clanTag,
+	}
+}
+
+// Poll will fetch the clan and its current war and return the events since the
+// last poll. The first snapshot of the clan and of its war are only recorded, so
+// restarting a watcher does not announce the members and war attacks it already
+// knew about again. A failed war fetch is kept in WarError and does not stop the
+// member events from being returned
+func (w *Watcher) Poll() ([]Event, error) {
+	clan, err := w.client.Clan.Get(w.clanTag)
+	if err != nil {
+		return nil, fmt.Errorf("could not get clan: %s", err.Error())
+	}
+	events := ClanEvents(w.clan, clan, time.Now())
+	w.clan = clan
+
+	currentWar, err := w.client.Clan.GetCurrentWar(w.clanTag)
+	if err != nil {
+		w.WarError = fmt.Errorf("could not get current war: %s", err.Error())
+		return events, nil
+	}
+	w.WarError = nil
+
+	if w.war != nil {
+		events = append(events, WarEvents(w.war, currentWar)...)
+	}
+	w.war = currentWar
+
+	return events, nil
+}
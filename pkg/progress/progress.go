@@ -0,0 +1,151 @@
+package progress
+
+import (
+	"sort"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/gamedata"
+)
+
+// offence holds the categories counted towards a players offence
+var offence = []gamedata.Category{gamedata.Troop, gamedata.SiegeMachine, gamedata.Spell,
+	gamedata.Hero, gamedata.Pet}
+
+// Progress holds how far a players offence is towards the max levels of a town hall
+type Progress struct {
+	Townhall int
+	// Levels is the sum of the players levels, each capped at the max level for the
+	// town hall
+	Levels    int
+	MaxLevels int
+}
+
+// Percent is the percentage of offence levels maxed for the town hall
+func (p *Progress) Percent() float64 {
+	if p.MaxLevels == 0 {
+		return 100
+	}
+	return float64(p.Levels) / float64(p.MaxLevels) * 100
+}
+
+// Remaining holds the levels left in a category to max it for the players town hall
+type Remaining struct {
+	Category gamedata.Category
+	Levels   int
+}
+
+// HeroProgress holds a heroes level against its max levels
+type HeroProgress struct {
+	Name  string
+	Level int
+	// Max is the max level for the players town hall
+	Max int
+	// PreviousMax is the max level for the town hall below the players
+	PreviousMax int
+}
+
+// Rushed reports whether the hero is below the max level of the previous town hall
+func (h *HeroProgress) Rushed() bool {
+	return h.Level < h.PreviousMax
+}
+
+// Report holds how rushed a player is
+type Report struct {
+	Tag      string
+	Name     string
+	Townhall int
+	// Current is the players progress towards their current town hall
+	Current Progress
+	// Previous is the players progress towards the town hall below theirs
+	Previous  Progress
+	Remaining []*Remaining
+	// Heroes lists the heroes that are below the max level for the players town hall
+	Heroes []*HeroProgress
+}
+
+// Rushed reports whether the player upgraded their town hall before maxing the
+// offence of their previous town hall
+func (r *Report) Rushed() bool {
+	return r.Previous.Levels < r.Previous.MaxLevels
+}
+
+// Total will add up the remaining levels of every category
+func (r *Report) Total() Remaining {
+	var total Remaining
+	for _, remaining := range r.Remaining {
+		total.Levels += remaining.Levels
+	}
+	return total
+}
+
+// PlayerProgress will work out how rushed a player is using the given catalogue. The
+// bundled catalogue is used if c is nil
+func PlayerProgress(player *goclash.Player, c *gamedata.Catalogue) *Report {
+	if c == nil {
+		c = gamedata.Default()
+	}
+
+	levels := make(map[string]int)
+	for _, group := range [][]goclash.Troop{player.Troops, player.Heros, player.Spells} {
+		for _, troop := range group {
+			if troop.Village == "" || troop.Village == "home" {
+				levels[troop.Name] = troop.Level
+			}
+		}
+	}
+
+	report := Report{
+		Tag:      player.Tag,
+		Name:     player.Name,
+		Townhall: player.TownhallLevel,
+		Current:  Progress{Townhall: player.TownhallLevel},
+		Previous: Progress{Townhall: player.TownhallLevel - 1},
+	}
+
+	for _, category := range offence {
+		remaining := Remaining{Category: category}
+		for _, item := range c.ByCategory(category) {
+			// super troops share the levels of their base troop
+			if item.SuperTroopOf != "" || (item.Village != "" && item.Village != "home") {
+				continue
+			}
+			level := levels[item.Name]
+			addProgress(&report.Current, item, level)
+			addProgress(&report.Previous, item, level)
+
+			max := item.MaxLevelForTownhall(report.Townhall)
+			if level < max {
+				remaining.Levels += max - level
+			}
+
+			if category == gamedata.Hero && max > 0 && level < max {
+				report.Heroes = append(report.Heroes, &HeroProgress{
+					Name:        item.Name,
+					Level:       level,
+					Max:         max,
+					PreviousMax: item.MaxLevelForTownhall(report.Previous.Townhall),
+				})
+			}
+		}
+		if remaining.Levels > 0 {
+			report.Remaining = append(report.Remaining, &remaining)
+		}
+	}
+
+	sort.SliceStable(report.Heroes, func(i, j int) bool {
+		return report.Heroes[i].Max-report.Heroes[i].Level >
+			report.Heroes[j].Max-report.Heroes[j].Level
+	})
+
+	return &report
+}
+
+// addProgress will add an items level to the progress towards a town hall
+func addProgress(p *Progress, item *gamedata.Item, level int) {
+	max := item.MaxLevelForTownhall(p.Townhall)
+	if level > max {
+		level = max
+	}
+	p.Levels += level
+	p.MaxLevels += max
+}
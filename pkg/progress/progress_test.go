@@ -0,0 +1,60 @@
+package progress_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/gamedata"
+	"github.com/joshturge/goclash/pkg/progress"
+)
+
+const catalogue = `{"items": [
+	{"name": "Barbarian", "category": "troop", "village": "home", "levels": [
+		{"level": 1, "townhall": 1},
+		{"level": 2, "townhall": 9},
+		{"level": 3, "townhall": 10}]},
+	{"name": "Super Barbarian", "category": "troop", "village": "home", "superTroopOf": "Barbarian"},
+	{"name": "Poison Spell", "category": "spell", "village": "home", "levels": [
+		{"level": 1, "townhall": 10}]},
+	{"name": "Barbarian King", "category": "hero", "village": "home", "levels": [
+		{"level": 1, "townhall": 9},
+		{"level": 2, "townhall": 9},
+		{"level": 3, "townhall": 10}]}
+]}`
+
+func TestPlayerProgress(t *testing.T) {
+	c, err := gamedata.Parse(strings.NewReader(catalogue))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := progress.PlayerProgress(&goclash.Player{
+		TownhallLevel: 10,
+		Troops:        []goclash.Troop{{Name: "Barbarian", Level: 2, Village: "home"}},
+		Heros:         []goclash.Troop{{Name: "Barbarian King", Level: 1, Village: "home"}},
+	}, c)
+
+	if report.Current.Levels != 3 || report.Current.MaxLevels != 7 {
+		t.Errorf("unexpected current progress: %+v", report.Current)
+	}
+	if report.Previous.Levels != 3 || report.Previous.MaxLevels != 4 || !report.Rushed() {
+		t.Errorf("unexpected previous progress: %+v", report.Previous)
+	}
+	if got := report.Previous.Percent(); got != 75 {
+		t.Errorf("Wanted: 75\tGot: %f", got)
+	}
+
+	if len(report.Heroes) != 1 || !report.Heroes[0].Rushed() || report.Heroes[0].Max != 3 {
+		t.Errorf("unexpected heroes: %+v", report.Heroes)
+	}
+
+	if len(report.Remaining) != 3 || report.Remaining[0].Category != gamedata.Troop ||
+		report.Remaining[0].Levels != 1 {
+		t.Errorf("unexpected remaining upgrades: %+v", report.Remaining)
+	}
+	total := report.Total()
+	if total.Levels != 4 {
+		t.Errorf("unexpected total: %+v", total)
+	}
+}
@@ -0,0 +1,297 @@
+package recruit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/gamedata"
+)
+
+const (
+	// DefaultWorkers is how many players are fetched at once
+	DefaultWorkers = 4
+	// DefaultInterval is the least amount of time between two requests
+	DefaultInterval = 50 * time.Millisecond
+	// warStarsCap is the amount of war stars that earns the full war star score
+	warStarsCap = 1000
+)
+
+// Weights control how much each part of a candidates score counts. Each part is
+// scored from 0 to 1 before it is weighted
+type Weights struct {
+	Townhall float64
+	Heroes   float64
+	WarStars float64
+	Activity float64
+}
+
+// DefaultWeights favour town hall and hero levels
+var DefaultWeights = Weights{Townhall: 4, Heroes: 3, WarStars: 2, Activity: 1}
+
+// Requirements are the least a player must have to be a candidate
+type Requirements struct {
+	MinTownhall int
+	MinWarStars int
+	// Active only keeps players that attacked or donated this season
+	Active bool
+}
+
+// Candidate is a player that could be recruited
+type Candidate struct {
+	Player *goclash.Player
+	// Clan is the clan the player was found in
+	Clan *goclash.Clan
+	// ClanActivity is the share of the clans members that donated this season
+	ClanActivity float64
+	Score        float64
+}
+
+// Scanner searches for clans and scores their members as recruitment candidates
+type Scanner struct {
+	client *goclash.Client
+
+	Weights      Weights
+	Requirements Requirements
+	// MaxClanActivity only scans clans where at most this share of members donated
+	// this season, so players in inactive or disbanding clans can be found. A value
+	// of 0 scans every clan
+	MaxClanActivity float64
+	// Exclude holds clan tags that are never scanned, such as our own clans
+	Exclude []string
+	// Workers is how many players are fetched at once
+	Workers int
+	// Interval is the least amount of time between two requests
+	Interval time.Duration
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// NewScanner will create a new Scanner with the default weights
+func NewScanner(client *goclash.Client) *Scanner {
+	return &Scanner{
+		client:   client,
+		Weights:  DefaultWeights,
+		Workers:  DefaultWorkers,
+		Interval: DefaultInterval,
+	}
+}
+
+// Scan will run the clan search, score the members of every matching clan and
+// return the best n candidates, every candidate if n is 0. Every page of the
+// search is throttled like the other requests of the scanner. Clans and players
+// that could not be fetched are skipped and reported in the error, which is
+// returned along with the candidates that were found
+func (s *Scanner) Scan(search *goclash.ClanSearch, n int) ([]*Candidate, error) {
+	clans, err := search.Throttle(s.wait).Run()
+	if err != nil {
+		return nil, fmt.Errorf("could not search for clans: %s", err.Error())
+	}
+
+	var (
+		candidates []*Candidate
+		failed     []string
+		townhall   = maxTownhall()
+	)
+	for _, clan := range clans {
+		if s.excluded(clan.Tag) {
+			continue
+		}
+
+		s.wait()
+		members, err := s.client.Clan.GetMembers(clan.Tag, nil)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("members of %s: %s", clan.Tag, err.Error()))
+			continue
+		}
+
+		activity := ClanActivity(members)
+		if s.MaxClanActivity > 0 && activity > s.MaxClanActivity {
+			continue
+		}
+
+		found, players := s.scanMembers(clan, members, activity, townhall)
+		candidates = append(candidates, found...)
+		failed = append(failed, players...)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	if n > 0 && len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	if len(failed) > 0 {
+		return candidates, fmt.Errorf("could not get %s", strings.Join(failed, ", "))
+	}
+	return candidates, nil
+}
+
+// scanMembers will fetch and score the members of a clan using the workers, where
+// townhall is the highest town hall level. It returns the players that could not
+// be fetched along with the candidates
+func (s *Scanner) scanMembers(clan *goclash.Clan, members []*goclash.Member,
+	activity float64, townhall int) ([]*Candidate, []string) {
+	workers := s.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		candidates []*Candidate
+		failed     []string
+		tags       = make(chan string)
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for tag := range tags {
+				s.wait()
+				player, err := s.client.Player.Get(tag)
+
+				mu.Lock()
+				if err != nil {
+					failed = append(failed, fmt.Sprintf("player %s: %s", tag, err.Error()))
+				} else if s.Requirements.Met(player) {
+					candidates = append(candidates, &Candidate{
+						Player:       player,
+						Clan:         clan,
+						ClanActivity: activity,
+						Score:        s.Weights.score(player, townhall),
+					})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, member := range members {
+		tags <- member.Tag
+	}
+	close(tags)
+	wg.Wait()
+
+	return candidates, failed
+}
+
+func (s *Scanner) excluded(tag string) bool {
+	for _, exclude := range s.Exclude {
+		if t, err := goclash.ParseTag(exclude); err == nil && t.String() == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// wait will sleep until Interval has passed since the last request. The time of
+// the request is reserved under the lock, so the workers sleep at the same time
+func (s *Scanner) wait() {
+	s.mu.Lock()
+	now := time.Now()
+	wait := s.Interval - now.Sub(s.last)
+	if wait < 0 {
+		wait = 0
+	}
+	s.last = now.Add(wait)
+	s.mu.Unlock()
+
+	time.Sleep(wait)
+}
+
+// Met reports whether a player meets the requirements
+func (r *Requirements) Met(player *goclash.Player) bool {
+	if player.TownhallLevel < r.MinTownhall || player.WarStars < r.MinWarStars {
+		return false
+	}
+	return !r.Active || active(player)
+}
+
+// Score will score a player with the weights
+func (w *Weights) Score(player *goclash.Player) float64 {
+	return w.score(player, maxTownhall())
+}
+
+// score will score a player with the weights, where townhall is the highest town
+// hall level
+func (w *Weights) score(player *goclash.Player, townhall int) float64 {
+	score := w.Townhall * ratio(player.TownhallLevel, townhall)
+	score += w.Heroes * HeroProgress(player)
+	score += w.WarStars * ratio(player.WarStars, warStarsCap)
+	if active(player) {
+		score += w.Activity
+	}
+	return score
+}
+
+// HeroProgress is the share of hero levels a player has out of the max for their
+// town hall, 1 if they have no heroes to upgrade
+func HeroProgress(player *goclash.Player) float64 {
+	var levels, max int
+	for _, hero := range player.Heros {
+		if hero.Village != "" && hero.Village != "home" {
+			continue
+		}
+		heroMax := hero.MaxLevel
+		if item, ok := gamedata.Default().Lookup(hero.Name); ok {
+			heroMax = item.MaxLevelForTownhall(player.TownhallLevel)
+		}
+		if hero.Level > heroMax {
+			heroMax = hero.Level
+		}
+		levels += hero.Level
+		max += heroMax
+	}
+	if max == 0 {
+		return 1
+	}
+	return float64(levels) / float64(max)
+}
+
+// ClanActivity is the share of members that donated or received troops this season
+func ClanActivity(members []*goclash.Member) float64 {
+	if len(members) == 0 {
+		return 0
+	}
+	active := 0
+	for _, member := range members {
+		if member.Donations > 0 || member.DonationsReceived > 0 {
+			active++
+		}
+	}
+	return float64(active) / float64(len(members))
+}
+
+// active reports whether a player attacked or donated this season
+func active(player *goclash.Player) bool {
+	return player.AttackWins > 0 || player.Donations > 0 || player.DonationsReceived > 0
+}
+
+// maxTownhall is the highest town hall level in the game data
+func maxTownhall() int {
+	max := 0
+	for _, item := range gamedata.Default().Items {
+		for _, l := range item.Levels {
+			if l.Townhall > max {
+				max = l.Townhall
+			}
+		}
+	}
+	return max
+}
+
+func ratio(value, max int) float64 {
+	if max <= 0 || value >= max {
+		return 1
+	}
+	if value <= 0 {
+		return 0
+	}
+	return float64(value) / float64(max)
+}
@@ -0,0 +1,106 @@
+package recruit_test
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/recruit"
+)
+
+var responses = map[string]string{
+	"/clans": `{"items": [{"tag": "#2PP", "name": "dead"}, {"tag": "#8QU", "name": "busy"},
+		{"tag": "#9LY", "name": "ours"}]}`,
+	"/clans/#2PP/members": `{"items": [{"tag": "#P2", "donations": 0}, {"tag": "#P8", "donations": 0},
+		{"tag": "#P9", "donations": 10}]}`,
+	"/clans/#8QU/members": `{"items": [{"tag": "#PP", "donations": 50}]}`,
+	"/players/#P2": `{"tag": "#P2", "townhallLevel": 13, "warStars": 900, "attackWins": 5,
+		"heroes": [{"name": "Barbarian King", "level": 70, "village": "home"}]}`,
+	"/players/#P8": `{"tag": "#P8", "townhallLevel": 13, "warStars": 100,
+		"heroes": [{"name": "Barbarian King", "level": 20, "village": "home"}]}`,
+	"/players/#P9": `{"tag": "#P9", "townhallLevel": 8, "warStars": 50, "donations": 10}`,
+}
+
+func TestScanner(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"reason": "notFound"}`))
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client, err := goclash.NewClient("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	client.SetLogger(log.New(ioutil.Discard, "", 0))
+
+	scanner := recruit.NewScanner(client)
+	scanner.Interval = 0
+	scanner.MaxClanActivity = 0.5
+	scanner.Exclude = []string{"9ly"}
+	scanner.Requirements = recruit.Requirements{MinTownhall: 9}
+
+	candidates, err := scanner.Scan(client.Clan.NewSearch().MinClanLevel(5), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 2 {
+		t.Fatalf("expected the two town hall 13 players of the inactive clan but got %d", len(candidates))
+	}
+	if candidates[0].Player.Tag != "#P2" || candidates[0].Score <= candidates[1].Score ||
+		candidates[0].Clan.Tag != "#2PP" {
+		t.Errorf("unexpected ranking: %+v %+v", candidates[0], candidates[1])
+	}
+
+	shortlist, err := scanner.Scan(client.Clan.NewSearch().MinClanLevel(5), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shortlist) != 1 {
+		t.Errorf("expected a shortlist of 1 but got %d", len(shortlist))
+	}
+}
+
+func TestScannerFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok || r.URL.Path == "/players/#P8" || r.URL.Path == "/clans/#8QU/members" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"reason": "notFound"}`))
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client, err := goclash.NewClient("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	client.SetLogger(log.New(ioutil.Discard, "", 0))
+
+	scanner := recruit.NewScanner(client)
+	scanner.Interval = 0
+	scanner.Exclude = []string{"9ly"}
+	scanner.Requirements = recruit.Requirements{MinTownhall: 9}
+
+	candidates, err := scanner.Scan(client.Clan.NewSearch().MinClanLevel(5), 0)
+	if err == nil || !strings.Contains(err.Error(), "player #P8") || !strings.Contains(err.Error(), "members of #8QU") {
+		t.Errorf("expected the failed player and clan to be reported: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].Player.Tag != "#P2" {
+		t.Errorf("expected the players that could be fetched to be kept: %+v", candidates)
+	}
+}
@@ -0,0 +1,244 @@
+package roster
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/progress"
+)
+
+const (
+	// MinTeamSize and MaxTeamSize are the smallest and largest wars that can be
+	// started. Team sizes go up in steps of TeamSizeStep
+	MinTeamSize  = 5
+	MaxTeamSize  = 50
+	TeamSizeStep = 5
+)
+
+// ValidTeamSize reports whether a war can be started with the team size
+func ValidTeamSize(size int) bool {
+	return size >= MinTeamSize && size <= MaxTeamSize && size%TeamSizeStep == 0
+}
+
+// Preference is whether a member wants to be in the next war
+type Preference int
+
+// Preferences a member can have
+const (
+	Undecided Preference = iota
+	OptIn
+	OptOut
+)
+
+// Strategy is how spots not taken by opted in members are filled
+type Strategy string
+
+// Strategies the planner can use
+const (
+	// Strongest fills the roster with the heaviest members
+	Strongest Strategy = "strongest"
+	// Rotation fills the roster with members that sat out the last war first
+	Rotation Strategy = "rotation"
+	// Balanced fills the roster with members spread evenly across the weights of
+	// the clan, so the roster is not only its heaviest or lightest members
+	Balanced Strategy = "balanced"
+)
+
+// Weight estimates the war weight of a player. Town hall level counts the most,
+// followed by hero levels and how much of their offence is maxed
+func Weight(player *goclash.Player) int {
+	weight := player.TownhallLevel * 1000
+	for _, hero := range player.Heros {
+		if hero.Village == "" || hero.Village == "home" {
+			weight += hero.Level * 5
+		}
+	}
+	report := progress.PlayerProgress(player, nil)
+	return weight + int(report.Current.Percent()*2)
+}
+
+// Entry is a member in a roster
+type Entry struct {
+	Player *goclash.Player
+	Weight int
+	// MapPosition is the expected position of the member on the war map
+	MapPosition int
+	Preference  Preference
+}
+
+// Roster is a proposed war line up
+type Roster struct {
+	Strategy Strategy
+	TeamSize int
+	// Members are in the expected map order, heaviest first
+	Members []*Entry
+	// Bench holds eligible members that did not make the roster
+	Bench  []*Entry
+	Weight int
+}
+
+// Planner proposes war rosters from the members of a clan
+type Planner struct {
+	players []*goclash.Player
+
+	// Preferences is keyed by player tag. Members without a preference are
+	// Undecided
+	Preferences map[string]Preference
+	// LastWar holds the tags of members that were in the last war, used by the
+	// Rotation strategy
+	LastWar []string
+}
+
+// NewPlanner will create a new Planner for the players
+func NewPlanner(players []*goclash.Player) *Planner {
+	return &Planner{players: players, Preferences: make(map[string]Preference)}
+}
+
+// FetchPlayers will get the profile of every member of a clan
+func FetchPlayers(client *goclash.Client, clanTag string) ([]*goclash.Player, error) {
+	members, err := client.Clan.GetMembers(clanTag, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not get members: %s", err.Error())
+	}
+
+	players := make([]*goclash.Player, 0, len(members))
+	for _, member := range members {
+		player, err := client.Player.Get(member.Tag)
+		if err != nil {
+			return nil, fmt.Errorf("could not get member %s: %s", member.Tag, err.Error())
+		}
+		players = append(players, player)
+	}
+	return players, nil
+}
+
+// Propose will propose a roster of size members using the strategy. Members that
+// opted in are always included if there is room, members that opted out never are
+func (p *Planner) Propose(size int, strategy Strategy) (*Roster, error) {
+	if !ValidTeamSize(size) {
+		return nil, fmt.Errorf("team size must be between %d and %d in steps of %d", MinTeamSize,
+			MaxTeamSize, TeamSizeStep)
+	}
+	if strategy != Strongest && strategy != Rotation && strategy != Balanced {
+		return nil, fmt.Errorf("unknown strategy %q", strategy)
+	}
+
+	played := make(map[string]bool, len(p.LastWar))
+	for _, tag := range p.LastWar {
+		played[tag] = true
+	}
+
+	var optedIn, undecided []*Entry
+	for _, player := range p.players {
+		entry := &Entry{Player: player, Weight: Weight(player), Preference: p.Preferences[player.Tag]}
+		switch entry.Preference {
+		case OptIn:
+			optedIn = append(optedIn, entry)
+		case Undecided:
+			undecided = append(undecided, entry)
+		}
+	}
+	if len(optedIn)+len(undecided) < size {
+		return nil, fmt.Errorf("only %d members are available for a %d v %d war",
+			len(optedIn)+len(undecided), size, size)
+	}
+
+	byWeight(optedIn)
+	byWeight(undecided)
+	switch strategy {
+	case Rotation:
+		sort.SliceStable(undecided, func(i, j int) bool {
+			return !played[undecided[i].Player.Tag] && played[undecided[j].Player.Tag]
+		})
+	case Balanced:
+		undecided = spread(undecided, size-len(optedIn))
+	}
+
+	roster := Roster{Strategy: strategy, TeamSize: size}
+	for _, entry := range append(optedIn, undecided...) {
+		if len(roster.Members) < size {
+			roster.Members = append(roster.Members, entry)
+			roster.Weight += entry.Weight
+			continue
+		}
+		roster.Bench = append(roster.Bench, entry)
+	}
+
+	byWeight(roster.Members)
+	byWeight(roster.Bench)
+	for i, entry := range roster.Members {
+		entry.MapPosition = i + 1
+	}
+
+	return &roster, nil
+}
+
+// Proposals will propose a roster with every strategy, the heaviest first
+func (p *Planner) Proposals(size int) ([]*Roster, error) {
+	var rosters []*Roster
+	for _, strategy := range []Strategy{Strongest, Rotation, Balanced} {
+		roster, err := p.Propose(size, strategy)
+		if err != nil {
+			return nil, err
+		}
+		rosters = append(rosters, roster)
+	}
+	sort.SliceStable(rosters, func(i, j int) bool {
+		return rosters[i].Weight > rosters[j].Weight
+	})
+	return rosters, nil
+}
+
+// spread will move n entries evenly spaced through entries to the front, keeping
+// the order of the rest
+func spread(entries []*Entry, n int) []*Entry {
+	if n <= 0 || n >= len(entries) {
+		return entries
+	}
+
+	picked := make(map[int]bool, n)
+	for k := 0; k < n; k++ {
+		if n == 1 {
+			picked[0] = true
+			break
+		}
+		picked[k*(len(entries)-1)/(n-1)] = true
+	}
+
+	spreadOut := make([]*Entry, 0, len(entries))
+	var rest []*Entry
+	for i, entry := range entries {
+		if picked[i] {
+			spreadOut = append(spreadOut, entry)
+			continue
+		}
+		rest = append(rest, entry)
+	}
+	return append(spreadOut, rest...)
+}
+
+// byWeight will sort entries heaviest first
+func byWeight(entries []*Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Weight > entries[j].Weight
+	})
+}
+
+// WriteTable will write the expected map order of the roster as a table
+func (r *Roster) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "#\tTag\tName\tTH\tWeight"); err != nil {
+		return fmt.Errorf("could not write table header: %s", err.Error())
+	}
+	for _, entry := range r.Members {
+		_, err := fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\n", entry.MapPosition, entry.Player.Tag,
+			entry.Player.Name, entry.Player.TownhallLevel, entry.Weight)
+		if err != nil {
+			return fmt.Errorf("could not write table row: %s", err.Error())
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,89 @@
+package roster_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/roster"
+)
+
+func TestPlanner(t *testing.T) {
+	var players []*goclash.Player
+	for i := 0; i < 8; i++ {
+		players = append(players, &goclash.Player{
+			Tag:           fmt.Sprintf("#P%d", i),
+			Name:          fmt.Sprintf("member %d", i),
+			TownhallLevel: 13 - i,
+		})
+	}
+
+	planner := roster.NewPlanner(players)
+	planner.Preferences["#P0"] = roster.OptOut
+	planner.Preferences["#P7"] = roster.OptIn
+	planner.LastWar = []string{"#P1", "#P2"}
+
+	strongest, err := planner.Propose(5, roster.Strongest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tags []string
+	for _, entry := range strongest.Members {
+		tags = append(tags, entry.Player.Tag)
+	}
+	if got := strings.Join(tags, " "); got != "#P1 #P2 #P3 #P4 #P7" {
+		t.Errorf("unexpected roster: %s", got)
+	}
+	if strongest.Members[0].MapPosition != 1 || strongest.Members[4].MapPosition != 5 || len(strongest.Bench) != 2 {
+		t.Errorf("unexpected map order: %+v", strongest)
+	}
+
+	rotation, err := planner.Propose(5, roster.Rotation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range rotation.Members {
+		if entry.Player.Tag == "#P1" || entry.Player.Tag == "#P2" {
+			t.Errorf("expected %s to sit out after playing the last war", entry.Player.Tag)
+		}
+	}
+
+	balanced, err := planner.Propose(5, roster.Balanced)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags = nil
+	for _, entry := range balanced.Members {
+		tags = append(tags, entry.Player.Tag)
+	}
+	if got := strings.Join(tags, " "); got != "#P1 #P2 #P4 #P6 #P7" {
+		t.Errorf("expected the roster to be spread across the weights: %s", got)
+	}
+
+	var buf bytes.Buffer
+	if err := strongest.WriteTable(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "member 7") {
+		t.Errorf("unexpected table:\n%s", buf.String())
+	}
+
+	if err := strongest.WriteTable(failingWriter{}); err == nil {
+		t.Error("expected the write error to be returned")
+	}
+
+	for _, size := range []int{0, 7, 10, 55} {
+		if _, err := planner.Propose(size, roster.Strongest); err == nil {
+			t.Errorf("expected a team size of %d to be rejected", size)
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
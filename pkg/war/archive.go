@@ -0,0 +1,160 @@
+package war
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+)
+
+// placeholderTag is the war tag given to league rounds that have not been drawn yet
+const placeholderTag = "#0"
+
+// Archiver polls the current war of a clan, and optionally its war league wars,
+// storing every snapshot in a History so the attacks are kept once the war is no
+// longer returned by the API
+type Archiver struct {
+	client  *goclash.Client
+	clanTag string
+
+	History *History
+	// Path is the file the history is saved to whenever a poll stores a war. The
+	// history is only kept in memory if Path is empty
+	Path string
+	// League also archives every war in the clan's war league group. Getting the
+	// league group fails while the clan is not in a league, so it should only be
+	// set during league week
+	League bool
+
+	// others holds the war tags of league wars between other clans of the group
+	others map[string]bool
+}
+
+// NewArchiver will create a new Archiver for a clan with an empty History
+func NewArchiver(client *goclash.Client, clanTag string) *Archiver {
+	if tag, err := goclash.ParseTag(clanTag); err == nil {
+		clanTag = tag.String()
+	}
+	return &Archiver{
+		client:  client,
+		clanTag: clanTag,
+		History: NewHistory(),
+		others:  make(map[string]bool),
+	}
+}
+
+// OpenArchiver will create a new Archiver that saves to path, loading the history
+// already saved there
+func OpenArchiver(client *goclash.Client, clanTag, path string) (*Archiver, error) {
+	a := NewArchiver(client, clanTag)
+	a.Path = path
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return a, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read war history: %s", err.Error())
+	}
+	if a.History, err = LoadHistory(bytes.NewReader(b)); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Poll will store the current state of the clan's wars. It returns the wars that
+// were seen in their final state for the first time. A war that can not be fetched
+// does not stop the others from being archived, the failures are reported in the
+// error once the history has been saved. The current war of a clan with a private
+// war log can not be fetched, which is not an error so its league wars are still
+// archived. League wars already stored in their final state, and league wars
+// between other clans of the group, are not fetched again
+func (a *Archiver) Poll() ([]*goclash.War, error) {
+	now := time.Now().UTC()
+	var (
+		ended  []*goclash.War
+		failed []string
+		stored bool
+	)
+
+	store := func(w *goclash.War, warTag string) {
+		alreadyEnded := a.History.ended(w)
+		if !a.History.Add(w, now) {
+			return
+		}
+		stored = true
+		if warTag != "" {
+			a.History.setWarTag(w, warTag)
+		}
+		if w.State == "warEnded" && !alreadyEnded {
+			ended = append(ended, w)
+		}
+	}
+
+	var errResp *goclash.ErrorResponse
+	currentWar, err := a.client.Clan.GetCurrentWar(a.clanTag)
+	switch {
+	case err == nil:
+		store(currentWar, "")
+	case errors.As(err, &errResp) && errResp.StatusCode == http.StatusForbidden:
+		// the war log is private
+	default:
+		failed = append(failed, fmt.Sprintf("current war: %s", err.Error()))
+	}
+
+	if a.League {
+		group, err := a.client.Clan.GetLeagueGroup(a.clanTag)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("league group: %s", err.Error()))
+		} else {
+			for _, round := range group.Rounds {
+				for _, warTag := range round.Tags {
+					if warTag == placeholderTag || a.others[warTag] || a.History.leagueWarEnded(warTag) {
+						continue
+					}
+					leagueWar, err := a.client.Clan.GetWarLeagueWar(warTag)
+					if err != nil {
+						failed = append(failed, fmt.Sprintf("war %s: %s", warTag, err.Error()))
+						continue
+					}
+					if leagueWar.Clan.Tag != a.clanTag && leagueWar.OpponentClan.Tag != a.clanTag {
+						a.others[warTag] = true
+						continue
+					}
+					store(leagueWar, warTag)
+				}
+			}
+		}
+	}
+
+	if stored && a.Path != "" {
+		if err = a.save(); err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return ended, fmt.Errorf("could not archive every war: %s", strings.Join(failed, ", "))
+	}
+	return ended, nil
+}
+
+// save will atomically replace the history file
+func (a *Archiver) save() error {
+	var buf bytes.Buffer
+	if err := a.History.Save(&buf); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(a.Path+".tmp", buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("could not write war history: %s", err.Error())
+	}
+	if err := os.Rename(a.Path+".tmp", a.Path); err != nil {
+		return fmt.Errorf("could not save war history: %s", err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package war_test
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/war"
+)
+
+func TestArchiver(t *testing.T) {
+	currentWar := `{"state": "inWar", "preparationStartTime": "20261001T080000.000Z",
+		"clan": {"tag": "#UV", "members": [{"tag": "#U1", "townhallLevel": 12,
+			"attacks": [{"order": 1, "attackerTag": "#U1", "defenderTag": "#C1", "stars": 2}]}]},
+		"opponent": {"tag": "#C", "members": [{"tag": "#C1", "townhallLevel": 12}]}}`
+	leagueWars, otherWars := 0, 0
+	failLeagueWar := true
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/clans/#UV/currentwar":
+			if currentWar == "" {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"reason": "accessDenied"}`))
+				return
+			}
+			w.Write([]byte(currentWar))
+		case "/clans/#UV/currentwar/leaguegroup":
+			w.Write([]byte(`{"state": "inWar", "rounds": [{"warTags": ["#P2", "#P8"]}, {"warTags": ["#0"]}]}`))
+		case "/clanwarleagues/wars/#P8":
+			otherWars++
+			if failLeagueWar {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(`{"reason": "inMaintenance"}`))
+				return
+			}
+			w.Write([]byte(`{"state": "inWar", "preparationStartTime": "20261002T080000.000Z",
+				"clan": {"tag": "#9"}, "opponent": {"tag": "#Q"}}`))
+		case "/clanwarleagues/wars/#P2":
+			leagueWars++
+			w.Write([]byte(`{"state": "warEnded", "preparationStartTime": "20261002T080000.000Z",
+				"clan": {"tag": "#8", "members": [{"tag": "#B1", "townhallLevel": 13,
+					"attacks": [{"order": 1, "attackerTag": "#B1", "defenderTag": "#U1", "stars": 3}]}]},
+				"opponent": {"tag": "#UV", "members": [{"tag": "#U1", "townhallLevel": 12}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"reason": "notFound"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := goclash.NewClient("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	client.SetLogger(log.New(ioutil.Discard, "", 0))
+
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "wars.json")
+
+	archiver, err := war.OpenArchiver(client, "#UV", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archiver.League = true
+
+	// a league war that can not be fetched does not stop the others being archived
+	ended, err := archiver.Poll()
+	if err == nil || !strings.Contains(err.Error(), "war #P8") {
+		t.Errorf("expected the failed league war to be reported: %v", err)
+	}
+	if len(ended) != 1 || ended[0].Clan.Tag != "#8" {
+		t.Fatalf("Wanted: the league war to have ended\tGot: %d wars", len(ended))
+	}
+	if saved, err := war.OpenArchiver(client, "#UV", path); err != nil || len(saved.History.Records()) != 2 {
+		t.Fatalf("expected the history to be saved despite the failure: %v", err)
+	}
+	failLeagueWar = false
+
+	currentWar = strings.Replace(currentWar, `"inWar"`, `"warEnded"`, 1)
+	if ended, err = archiver.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	if len(ended) != 1 || ended[0].Clan.Tag != "#UV" {
+		t.Fatalf("Wanted: the current war to have ended\tGot: %d wars", len(ended))
+	}
+	if leagueWars != 1 {
+		t.Errorf("Wanted: an ended league war to be fetched once\tGot: %d", leagueWars)
+	}
+	if _, err = archiver.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	if otherWars != 2 {
+		t.Errorf("Wanted: a league war between other clans to not be fetched again\tGot: %d", otherWars)
+	}
+
+	// the war is gone once the clan is no longer in it
+	currentWar = `{"state": "notInWar"}`
+	if ended, err = archiver.Poll(); err != nil || len(ended) != 0 {
+		t.Fatalf("Wanted: nothing new\tGot: %d wars, %v", len(ended), err)
+	}
+
+	// a private war log does not stop league wars being archived, and league wars
+	// that ended before a restart are not fetched again
+	currentWar = ""
+	reopened, err := war.OpenArchiver(client, "#UV", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reopened.League = true
+	if _, err = reopened.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	if leagueWars != 1 {
+		t.Errorf("Wanted: an ended league war to not be fetched after a restart\tGot: %d", leagueWars)
+	}
+
+	records := reopened.History.Records()
+	if len(records) != 2 {
+		t.Fatalf("Wanted: 2 archived wars\tGot: %d", len(records))
+	}
+	if records[0].War.State != "warEnded" || len(records[0].War.Clan.Team[0].Attacks) != 1 {
+		t.Errorf("the final state of the current war was not archived: %+v", records[0].War)
+	}
+}
@@ -0,0 +1,404 @@
+package war
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/joshturge/goclash/pkg/clash"
+)
+
+// StarRates holds the chance of an attack earning at least one, two and three stars
+type StarRates [3]float64
+
+// Expected is the amount of stars the attack is expected to earn
+func (sr StarRates) Expected() float64 {
+	return sr[0] + sr[1] + sr[2]
+}
+
+// HitRateModel estimates how an attacker will do against a defender
+type HitRateModel interface {
+	Rates(attacker, defender *goclash.WarMember) StarRates
+}
+
+// TownhallModel estimates hit rates from the town hall difference between the
+// defender and attacker alone. Differences outside the model use the closest one
+type TownhallModel map[int]StarRates
+
+// DefaultModel is a rough model of hit rates by town hall difference
+var DefaultModel = TownhallModel{
+	-2: {1, 1, 0.95},
+	-1: {1, 0.98, 0.85},
+	0:  {0.99, 0.9, 0.55},
+	1:  {0.95, 0.6, 0.2},
+	2:  {0.85, 0.3, 0.05},
+	3:  {0.6, 0.1, 0},
+}
+
+// Rates will get the hit rates for the town hall difference of the attack
+func (tm TownhallModel) Rates(attacker, defender *goclash.WarMember) StarRates {
+	diff := defender.TownhallLevel - attacker.TownhallLevel
+	if rates, ok := tm[diff]; ok {
+		return rates
+	}
+
+	closest, found := 0, false
+	for d := range tm {
+		if !found || abs(d-diff) < abs(closest-diff) || abs(d-diff) == abs(closest-diff) && d < closest {
+			closest, found = d, true
+		}
+	}
+	return tm[closest]
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// Assignment is a single attack an attacker is called to make
+type Assignment struct {
+	Attacker *goclash.WarMember
+	Defender *goclash.WarMember
+	Rates    StarRates
+	// Gain is the amount of new stars the attack is expected to add
+	Gain float64
+}
+
+// Plan holds the calls for every remaining attack of a clan
+type Plan struct {
+	Assignments []*Assignment
+	// ExpectedStars is the amount of stars the clan is expected to have once
+	// every call has been made
+	ExpectedStars float64
+}
+
+// defenderState holds the chance of a base currently being at zero to three stars
+type defenderState [4]float64
+
+func (ds defenderState) expected() float64 {
+	return ds[1] + 2*ds[2] + 3*ds[3]
+}
+
+// after will get the state of the base after an attack with the rates
+func (ds defenderState) after(rates StarRates) defenderState {
+	// chance of the attack getting exactly n stars
+	exact := [4]float64{1 - rates[0], rates[0] - rates[1], rates[1] - rates[2], rates[2]}
+
+	var next defenderState
+	for current, pc := range ds {
+		for stars, pa := range exact {
+			best := current
+			if stars > best {
+				best = stars
+			}
+			next[best] += pc * pa
+		}
+	}
+	return next
+}
+
+// Assign will assign the remaining attacks of the clan to bases of the opponent to
+// raise the expected amount of stars.
+//
+// When every member has a single attack the calls are an exact assignment of
+// attackers to bases that maximises the expected stars, with each base called at
+// most once. Attackers left over once every base has a call are then assigned
+// greedily. Wars with more attacks per member are only assigned greedily: attacks
+// are assigned one at a time to the attacker and base that add the most expected
+// stars at that point, which can miss the best plan, e.g. when a strong attacker
+// is used on a base a weaker one could also have taken
+func Assign(w *goclash.War, model HitRateModel) *Plan {
+	if model == nil {
+		model = DefaultModel
+	}
+	perMember := w.AttacksPerMember
+	if perMember == 0 {
+		perMember = DefaultAttacksPerMember
+	}
+
+	defenders := make([]*goclash.WarMember, len(w.OpponentClan.Team))
+	states := make([]defenderState, len(w.OpponentClan.Team))
+	for i := range w.OpponentClan.Team {
+		defender := &w.OpponentClan.Team[i]
+		defenders[i] = defender
+		stars := 0
+		if defender.OpponentAttacks > 0 {
+			stars = defender.BestOpponentAttack.Stars
+		}
+		if stars < 0 {
+			stars = 0
+		} else if stars > 3 {
+			stars = 3
+		}
+		states[i][stars] = 1
+	}
+
+	attackers := make([]*attacker, 0, len(w.Clan.Team))
+	for i := range w.Clan.Team {
+		member := &w.Clan.Team[i]
+		a := attacker{member: member, remaining: perMember - len(member.Attacks),
+			hit: make(map[string]bool, perMember)}
+		for _, attack := range member.Attacks {
+			a.hit[attack.DefenderTag] = true
+		}
+		if a.remaining > 0 {
+			attackers = append(attackers, &a)
+		}
+	}
+
+	p := planner{model: model, attackers: attackers, defenders: defenders, states: states}
+	if perMember == 1 {
+		p.assignExact()
+	}
+	p.assignGreedy()
+
+	for _, state := range p.states {
+		p.plan.ExpectedStars += state.expected()
+	}
+	sort.SliceStable(p.plan.Assignments, func(i, j int) bool {
+		return p.plan.Assignments[i].Attacker.MapPosition < p.plan.Assignments[j].Attacker.MapPosition
+	})
+
+	return &p.plan
+}
+
+// attacker is a member of the clan with attacks left to assign
+type attacker struct {
+	member    *goclash.WarMember
+	remaining int
+	// hit holds the tags of the bases the member attacked or is called on
+	hit map[string]bool
+}
+
+// planner holds the state of the bases while attacks are assigned
+type planner struct {
+	model     HitRateModel
+	attackers []*attacker
+	defenders []*goclash.WarMember
+	states    []defenderState
+	plan      Plan
+}
+
+// gain is the amount of stars an attack on the base at index i adds
+func (p *planner) gain(a *attacker, i int) (float64, StarRates) {
+	rates := p.model.Rates(a.member, p.defenders[i])
+	return p.states[i].after(rates).expected() - p.states[i].expected(), rates
+}
+
+// call will assign an attack and update the state of the base
+func (p *planner) call(a *attacker, i int, gain float64, rates StarRates) {
+	defender := p.defenders[i]
+	a.remaining--
+	a.hit[defender.Tag] = true
+	p.states[i] = p.states[i].after(rates)
+	p.plan.Assignments = append(p.plan.Assignments, &Assignment{
+		Attacker: a.member,
+		Defender: defender,
+		Rates:    rates,
+		Gain:     gain,
+	})
+}
+
+// assignExact will give each attacker at most one base, and each base at most one
+// attacker, so the total gain is the highest possible. Ties are broken towards
+// attacks closer to a mirror
+func (p *planner) assignExact() {
+	const tieBreak = 1e-7
+
+	cost := make([][]float64, len(p.attackers))
+	for r, a := range p.attackers {
+		cost[r] = make([]float64, len(p.defenders))
+		for i, defender := range p.defenders {
+			if a.hit[defender.Tag] {
+				continue
+			}
+			gain, _ := p.gain(a, i)
+			cost[r][i] = -gain + tieBreak*float64(abs(a.member.MapPosition-defender.MapPosition))
+		}
+	}
+
+	for r, i := range minCostAssignment(cost) {
+		a := p.attackers[r]
+		if i < 0 || a.hit[p.defenders[i].Tag] {
+			continue
+		}
+		if gain, rates := p.gain(a, i); gain > 1e-9 {
+			p.call(a, i, gain, rates)
+		}
+	}
+}
+
+// assignGreedy will assign the remaining attacks one at a time to the attacker and
+// base that add the most expected stars
+func (p *planner) assignGreedy() {
+	for {
+		var (
+			best      *attacker
+			bestIndex int
+			bestGain  float64
+			bestRates StarRates
+		)
+		for _, a := range p.attackers {
+			if a.remaining == 0 {
+				continue
+			}
+			for i, defender := range p.defenders {
+				if a.hit[defender.Tag] {
+					continue
+				}
+				gain, rates := p.gain(a, i)
+				if gain > bestGain+1e-9 || best != nil && gain > bestGain-1e-9 &&
+					closer(a.member, defender, best.member, p.defenders[bestIndex]) {
+					best, bestIndex, bestGain, bestRates = a, i, gain, rates
+				}
+			}
+		}
+		if best == nil {
+			return
+		}
+		p.call(best, bestIndex, bestGain, bestRates)
+	}
+}
+
+// minCostAssignment will pair rows with columns of the cost matrix so the total
+// cost is the lowest possible, using the Hungarian algorithm. Every row of the
+// smaller side is paired; the result holds the column of each row, -1 if the row
+// was not paired
+func minCostAssignment(cost [][]float64) []int {
+	rows := len(cost)
+	if rows == 0 || len(cost[0]) == 0 {
+		result := make([]int, rows)
+		for r := range result {
+			result[r] = -1
+		}
+		return result
+	}
+	cols := len(cost[0])
+
+	if rows > cols {
+		transposed := make([][]float64, cols)
+		for c := range transposed {
+			transposed[c] = make([]float64, rows)
+			for r := range cost {
+				transposed[c][r] = cost[r][c]
+			}
+		}
+		result := make([]int, rows)
+		for r := range result {
+			result[r] = -1
+		}
+		for c, r := range minCostAssignment(transposed) {
+			result[r] = c
+		}
+		return result
+	}
+
+	// potentials and matching are 1 indexed, column 0 is a virtual column
+	u := make([]float64, rows+1)
+	v := make([]float64, cols+1)
+	match := make([]int, cols+1)
+	way := make([]int, cols+1)
+	for r := 1; r <= rows; r++ {
+		match[0] = r
+		col := 0
+		minv := make([]float64, cols+1)
+		used := make([]bool, cols+1)
+		for c := range minv {
+			minv[c] = math.Inf(1)
+		}
+		for {
+			used[col] = true
+			row, delta, next := match[col], math.Inf(1), 0
+			for c := 1; c <= cols; c++ {
+				if used[c] {
+					continue
+				}
+				if cur := cost[row-1][c-1] - u[row] - v[c]; cur < minv[c] {
+					minv[c], way[c] = cur, col
+				}
+				if minv[c] < delta {
+					delta, next = minv[c], c
+				}
+			}
+			for c := 0; c <= cols; c++ {
+				if used[c] {
+					u[match[c]] += delta
+					v[c] -= delta
+				} else {
+					minv[c] -= delta
+				}
+			}
+			col = next
+			if match[col] == 0 {
+				break
+			}
+		}
+		for col != 0 {
+			prev := way[col]
+			match[col] = match[prev]
+			col = prev
+		}
+	}
+
+	result := make([]int, rows)
+	for c := 1; c <= cols; c++ {
+		if match[c] != 0 {
+			result[match[c]-1] = c - 1
+		}
+	}
+	return result
+}
+
+// closer reports whether the first attack is closer to a mirror than the second,
+// which is used to break ties between attacks with the same gain
+func closer(attacker, defender, otherAttacker, otherDefender *goclash.WarMember) bool {
+	return abs(attacker.MapPosition-defender.MapPosition) <
+		abs(otherAttacker.MapPosition-otherDefender.MapPosition)
+}
+
+// Call holds the attacks a member is called to make
+type Call struct {
+	Member  *goclash.WarMember
+	Targets []*Assignment
+}
+
+// CallList will group the assignments by attacker, in map order
+func (p *Plan) CallList() []*Call {
+	var calls []*Call
+	byTag := make(map[string]*Call)
+	for _, a := range p.Assignments {
+		call, ok := byTag[a.Attacker.Tag]
+		if !ok {
+			call = &Call{Member: a.Attacker}
+			byTag[a.Attacker.Tag] = call
+			calls = append(calls, call)
+		}
+		call.Targets = append(call.Targets, a)
+	}
+	return calls
+}
+
+// WriteTable will write the calls as a table in map order
+func (p *Plan) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "#\tAttacker\tTH\tTarget\tTarget TH\t3 Star\tGain"); err != nil {
+		return fmt.Errorf("could not write table header: %s", err.Error())
+	}
+	for _, a := range p.Assignments {
+		_, err := fmt.Fprintf(tw, "%d\t%s\t%d\t%d. %s\t%d\t%.0f%%\t%.2f\n", a.Attacker.MapPosition,
+			a.Attacker.Name, a.Attacker.TownhallLevel, a.Defender.MapPosition, a.Defender.Name,
+			a.Defender.TownhallLevel, a.Rates[2]*100, a.Gain)
+		if err != nil {
+			return fmt.Errorf("could not write table row: %s", err.Error())
+		}
+	}
+	if _, err := fmt.Fprintf(tw, "\t\t\t\t\tExpected stars\t%.2f\n", p.ExpectedStars); err != nil {
+		return fmt.Errorf("could not write table row: %s", err.Error())
+	}
+	return tw.Flush()
+}
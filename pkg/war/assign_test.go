@@ -0,0 +1,128 @@
+package war_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/war"
+)
+
+func TestAssign(t *testing.T) {
+	w := &goclash.War{
+		State:    "inWar",
+		TeamSize: 2,
+		Clan: goclash.WarClan{
+			Tag: "#CLAN",
+			Team: []goclash.WarMember{
+				{Tag: "#A1", Name: "alpha", MapPosition: 1, TownhallLevel: 14},
+				{Tag: "#A2", Name: "bravo", MapPosition: 2, TownhallLevel: 12,
+					Attacks: []goclash.Attack{
+						{AttackerTag: "#A2", DefenderTag: "#B2", Stars: 2, DestructionPercentage: 70},
+					}},
+			},
+		},
+		OpponentClan: goclash.WarClan{
+			Tag: "#OPP",
+			Team: []goclash.WarMember{
+				{Tag: "#B1", Name: "charlie", MapPosition: 1, TownhallLevel: 14},
+				{Tag: "#B2", Name: "delta", MapPosition: 2, TownhallLevel: 12, OpponentAttacks: 1,
+					BestOpponentAttack: goclash.Attack{Stars: 2, DestructionPercentage: 70}},
+			},
+		},
+	}
+
+	model := war.TownhallModel{
+		0: {1, 0.9, 0.5},
+		2: {0.8, 0.2, 0},
+	}
+	if rates := model.Rates(&w.Clan.Team[1], &w.OpponentClan.Team[0]); rates != model[2] {
+		t.Errorf("Wanted: %v\tGot: %v", model[2], rates)
+	}
+	if rates := model.Rates(&w.Clan.Team[0], &w.OpponentClan.Team[1]); rates != model[0] {
+		t.Errorf("Wanted: closest rates %v\tGot: %v", model[0], rates)
+	}
+
+	plan := war.Assign(w, model)
+	if len(plan.Assignments) != 3 {
+		t.Fatalf("Wanted: 3 calls\tGot: %d", len(plan.Assignments))
+	}
+
+	calls := plan.CallList()
+	if len(calls) != 2 || calls[0].Member.Tag != "#A1" || len(calls[0].Targets) != 2 {
+		t.Fatalf("alpha should have two calls first, got %+v", calls)
+	}
+	for _, call := range calls {
+		for _, target := range call.Targets {
+			if call.Member.Tag == "#A2" && target.Defender.Tag == "#B2" {
+				t.Error("bravo should not be called on a base they already hit")
+			}
+		}
+	}
+	if calls[1].Targets[0].Defender.Tag != "#B1" {
+		t.Errorf("Wanted: bravo on #B1\tGot: %s", calls[1].Targets[0].Defender.Tag)
+	}
+
+	// charlie is hit by alpha then bravo, delta's two stars are cleaned up by alpha
+	if plan.ExpectedStars < 4.5 || plan.ExpectedStars > 6 {
+		t.Errorf("expected stars out of range: %.2f", plan.ExpectedStars)
+	}
+
+	var buf bytes.Buffer
+	if err := plan.WriteTable(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Expected stars") {
+		t.Errorf("table is missing the expected stars:\n%s", buf.String())
+	}
+
+	// star counts outside 0 to 3 from a bad response are clamped
+	w.OpponentClan.Team[1].BestOpponentAttack.Stars = 7
+	if plan := war.Assign(w, model); plan.ExpectedStars < 3 {
+		t.Errorf("expected delta to count as three stars: %.2f", plan.ExpectedStars)
+	}
+}
+
+func TestAssignExact(t *testing.T) {
+	w := &goclash.War{
+		State:            "inWar",
+		TeamSize:         2,
+		AttacksPerMember: 1,
+		Clan: goclash.WarClan{Tag: "#CLAN", Team: []goclash.WarMember{
+			{Tag: "#A1", Name: "strong", MapPosition: 1, TownhallLevel: 14},
+			{Tag: "#A2", Name: "weak", MapPosition: 2, TownhallLevel: 12},
+		}},
+		OpponentClan: goclash.WarClan{Tag: "#OPP", Team: []goclash.WarMember{
+			{Tag: "#B1", Name: "x", MapPosition: 1, TownhallLevel: 14},
+			{Tag: "#B2", Name: "y", MapPosition: 2, TownhallLevel: 12},
+		}},
+	}
+
+	// picking the best single attack first sends the strong attacker to the
+	// weaker base, which leaves the weak attacker on the stronger one
+	plan := war.Assign(w, nil)
+	if len(plan.Assignments) != 2 {
+		t.Fatalf("Wanted: 2 calls\tGot: %d", len(plan.Assignments))
+	}
+	for _, a := range plan.Assignments {
+		if a.Attacker.MapPosition != a.Defender.MapPosition {
+			t.Errorf("Wanted: %s on their mirror\tGot: %s", a.Attacker.Name, a.Defender.Name)
+		}
+	}
+	if want := 2 * war.DefaultModel[0].Expected(); plan.ExpectedStars < want-1e-9 {
+		t.Errorf("Wanted: %.2f expected stars\tGot: %.2f", want, plan.ExpectedStars)
+	}
+
+	// with a second strong attacker the weak one is left over and cleans up greedily
+	w.Clan.Team = append(w.Clan.Team, goclash.WarMember{Tag: "#A3", Name: "extra", MapPosition: 3,
+		TownhallLevel: 14})
+	plan = war.Assign(w, nil)
+	calls := make(map[string]string)
+	for _, a := range plan.Assignments {
+		calls[a.Attacker.Tag] = a.Defender.Tag
+	}
+	if len(calls) != 3 || calls["#A1"] != "#B1" || calls["#A3"] != "#B2" {
+		t.Errorf("unexpected calls: %v", calls)
+	}
+}
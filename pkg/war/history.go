@@ -0,0 +1,219 @@
+package war
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+)
+
+// Record is the latest stored snapshot of a war
+type Record struct {
+	War *goclash.War `json:"war"`
+	// Armies holds the army used for an attack keyed by attack order, when it has
+	// been recorded
+	Armies map[int]string `json:"armies,omitempty"`
+	// CapturedAt is when the snapshot was taken
+	CapturedAt time.Time `json:"capturedAt"`
+	// WarTag is the war tag of a war league war, empty for other wars
+	WarTag string `json:"warTag,omitempty"`
+}
+
+// apiTimeFormat is the layout of timestamps returned by the API
+const apiTimeFormat = "20060102T150405.000Z"
+
+// apiTime encodes a time in the format the API uses so goclash.ClashTime can
+// decode it again
+type apiTime time.Time
+
+func (t apiTime) MarshalJSON() ([]byte, error) {
+	if time.Time(t).IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(`"` + time.Time(t).UTC().Format(apiTimeFormat) + `"`), nil
+}
+
+// storedWar is a war with its times encoded as the API returns them
+type storedWar struct {
+	*goclash.War
+	StartTime            apiTime `json:"startTime"`
+	PreparationStartTime apiTime `json:"preparationStartTime"`
+	EndTime              apiTime `json:"endTime"`
+	WarStartTime         apiTime `json:"warStartTime"`
+}
+
+// MarshalJSON encodes the record with the war times in the format of the API so
+// the saved history decodes into a goclash.War
+func (r Record) MarshalJSON() ([]byte, error) {
+	type record Record
+	stored := struct {
+		record
+		War *storedWar `json:"war"`
+	}{record: record(r)}
+	if r.War != nil {
+		stored.War = &storedWar{
+			War:                  r.War,
+			StartTime:            apiTime(r.War.StartTime.Time),
+			PreparationStartTime: apiTime(r.War.PreparationStartTime.Time),
+			EndTime:              apiTime(r.War.EndTime.Time),
+			WarStartTime:         apiTime(r.War.WarStartTime.Time),
+		}
+	}
+	return json.Marshal(stored)
+}
+
+// Key identifies a war by the two clans and when preparation started. It is the
+// same from both sides of the war
+func Key(w *goclash.War) string {
+	tags := []string{w.Clan.Tag, w.OpponentClan.Tag}
+	sort.Strings(tags)
+	return fmt.Sprintf("%s/%s/%d", tags[0], tags[1], w.PreparationStartTime.Unix())
+}
+
+// attackCount is the amount of attacks both clans have made in a war
+func attackCount(w *goclash.War) int {
+	count := 0
+	for _, member := range w.Clan.Team {
+		count += len(member.Attacks)
+	}
+	for _, member := range w.OpponentClan.Team {
+		count += len(member.Attacks)
+	}
+	return count
+}
+
+// History stores snapshots of wars so their attacks are kept once the war is
+// gone from the API. Only the most complete snapshot of each war is kept
+type History struct {
+	mu sync.Mutex
+	// Wars is keyed by Key
+	Wars map[string]*Record `json:"wars"`
+}
+
+// NewHistory will create an empty History
+func NewHistory() *History {
+	return &History{Wars: make(map[string]*Record)}
+}
+
+// LoadHistory will read a history saved with Save
+func LoadHistory(r io.Reader) (*History, error) {
+	h := NewHistory()
+	if err := json.NewDecoder(r).Decode(h); err != nil {
+		return nil, fmt.Errorf("could not decode war history: %s", err.Error())
+	}
+	if h.Wars == nil {
+		h.Wars = make(map[string]*Record)
+	}
+	for key, record := range h.Wars {
+		if record == nil || record.War == nil {
+			delete(h.Wars, key)
+		}
+	}
+	return h, nil
+}
+
+// Save will write the history as json
+func (h *History) Save(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := json.NewEncoder(w).Encode(h); err != nil {
+		return fmt.Errorf("could not encode war history: %s", err.Error())
+	}
+	return nil
+}
+
+// Add will store a snapshot of a war. Wars that have not started are ignored and
+// a snapshot only replaces the stored one if it has at least as many attacks.
+// Add reports whether the snapshot was stored
+func (h *History) Add(w *goclash.War, now time.Time) bool {
+	if w == nil || (w.State != "inWar" && w.State != "warEnded") || w.OpponentClan.Tag == "" {
+		return false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.Wars == nil {
+		h.Wars = make(map[string]*Record)
+	}
+
+	key := Key(w)
+	record, ok := h.Wars[key]
+	if !ok {
+		h.Wars[key] = &Record{War: w, CapturedAt: now}
+		return true
+	}
+	if attackCount(w) < attackCount(record.War) || record.War.State == "warEnded" && w.State != "warEnded" {
+		return false
+	}
+	record.War, record.CapturedAt = w, now
+	return true
+}
+
+// SetArmy will record the army used for the attack with the order in a stored war
+func (h *History) SetArmy(w *goclash.War, order int, army string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	record, ok := h.Wars[Key(w)]
+	if !ok {
+		return fmt.Errorf("war %s is not stored", Key(w))
+	}
+	if record.Armies == nil {
+		record.Armies = make(map[int]string)
+	}
+	record.Armies[order] = army
+	return nil
+}
+
+// Records will list the stored wars, the oldest first
+func (h *History) Records() []*Record {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	records := make([]*Record, 0, len(h.Wars))
+	for _, record := range h.Wars {
+		records = append(records, record)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		ti, tj := records[i].War.PreparationStartTime.Time, records[j].War.PreparationStartTime.Time
+		if ti.Equal(tj) {
+			return Key(records[i].War) < Key(records[j].War)
+		}
+		return ti.Before(tj)
+	})
+	return records
+}
+
+// ended reports whether the stored snapshot of a war is of the war once it ended
+func (h *History) ended(w *goclash.War) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	record, ok := h.Wars[Key(w)]
+	return ok && record.War.State == "warEnded"
+}
+
+// setWarTag will record the war tag of a stored war league war
+func (h *History) setWarTag(w *goclash.War, warTag string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if record, ok := h.Wars[Key(w)]; ok {
+		record.WarTag = warTag
+	}
+}
+
+// leagueWarEnded reports whether the war league war with the war tag is stored in
+// its final state
+func (h *History) leagueWarEnded(warTag string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, record := range h.Wars {
+		if record.WarTag == warTag && record.War.State == "warEnded" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package war_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/war"
+)
+
+func TestHistory(t *testing.T) {
+	prep := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	w := testWar()
+	w.PreparationStartTime = goclash.ClashTime{Time: prep}
+
+	history := war.NewHistory()
+	if history.Add(&goclash.War{State: "preparation", OpponentClan: goclash.WarClan{Tag: "#OPP"}}, prep) {
+		t.Error("a war in preparation should not be stored")
+	}
+	if !history.Add(w, prep.Add(24*time.Hour)) {
+		t.Fatal("the war should be stored")
+	}
+
+	// the same war from the opponent's side with fewer attacks
+	flipped := *w
+	flipped.Clan, flipped.OpponentClan = w.OpponentClan, w.Clan
+	flipped.Clan.Team = nil
+	if history.Add(&flipped, prep.Add(25*time.Hour)) {
+		t.Error("a snapshot with fewer attacks should not replace the stored war")
+	}
+	if war.Key(&flipped) != war.Key(w) {
+		t.Errorf("Wanted: the same key from both sides\tGot: %s and %s", war.Key(&flipped), war.Key(w))
+	}
+
+	if err := history.SetArmy(w, 3, "hybrid"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := history.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := war.LoadHistory(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := loaded.Records()
+	if len(records) != 1 {
+		t.Fatalf("Wanted: 1 war\tGot: %d", len(records))
+	}
+	if !records[0].War.PreparationStartTime.Equal(prep) {
+		t.Errorf("Wanted: %s\tGot: %s", prep, records[0].War.PreparationStartTime)
+	}
+	if records[0].Armies[3] != "hybrid" {
+		t.Errorf("Wanted: hybrid army\tGot: %q", records[0].Armies[3])
+	}
+}
+
+func TestHistoryTimes(t *testing.T) {
+	prep := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	w := testWar()
+	w.PreparationStartTime = goclash.ClashTime{Time: prep}
+	w.StartTime = goclash.ClashTime{Time: prep.Add(23 * time.Hour)}
+	w.EndTime = goclash.ClashTime{Time: prep.Add(47*time.Hour + 500*time.Millisecond)}
+
+	history := war.NewHistory()
+	history.Add(w, prep.Add(48*time.Hour))
+
+	var buf bytes.Buffer
+	if err := history.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	saved := buf.String()
+	for _, want := range []string{
+		`"preparationStartTime":"20260301T100000.000Z"`,
+		`"startTime":"20260302T090000.000Z"`,
+		`"endTime":"20260303T090000.500Z"`,
+		`"warStartTime":null`,
+	} {
+		if !strings.Contains(saved, want) {
+			t.Errorf("Wanted: %s in the saved history\tGot: %s", want, saved)
+		}
+	}
+
+	loaded, err := war.LoadHistory(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := loaded.Records()[0].War
+	if !got.StartTime.Equal(w.StartTime.Time) || !got.EndTime.Equal(w.EndTime.Time) {
+		t.Errorf("Wanted: %s - %s\tGot: %s - %s", w.StartTime, w.EndTime, got.StartTime, got.EndTime)
+	}
+	if !got.WarStartTime.IsZero() {
+		t.Errorf("Wanted: no war start time\tGot: %s", got.WarStartTime)
+	}
+}
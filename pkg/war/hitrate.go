@@ -0,0 +1,297 @@
+package war
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/joshturge/goclash/pkg/clash"
+)
+
+const (
+	// DefaultMinAttacks is the fewest attacks a matchup needs before a Model uses
+	// its own rates
+	DefaultMinAttacks = 10
+	// confidenceZ is the z score of the 95% confidence intervals
+	confidenceZ = 1.96
+)
+
+// Rate is the share of attacks that reached a star count
+type Rate struct {
+	Hits    int
+	Attacks int
+}
+
+// Value is the observed rate, 0 if there were no attacks
+func (r Rate) Value() float64 {
+	if r.Attacks == 0 {
+		return 0
+	}
+	return float64(r.Hits) / float64(r.Attacks)
+}
+
+// Interval is the 95% Wilson score confidence interval of the rate. With no
+// attacks the rate could be anything between 0 and 1
+func (r Rate) Interval() (low, high float64) {
+	if r.Attacks == 0 {
+		return 0, 1
+	}
+	n := float64(r.Attacks)
+	p := r.Value()
+	z2 := confidenceZ * confidenceZ
+
+	centre := (p + z2/(2*n)) / (1 + z2/n)
+	margin := confidenceZ * math.Sqrt(p*(1-p)/n+z2/(4*n*n)) / (1 + z2/n)
+	return math.Max(0, centre-margin), math.Min(1, centre+margin)
+}
+
+// String formats the rate with its confidence interval
+func (r Rate) String() string {
+	low, high := r.Interval()
+	return fmt.Sprintf("%.0f%% (%.0f-%.0f%%)", r.Value()*100, low*100, high*100)
+}
+
+// Tally counts attacks by how many stars they earned
+type Tally struct {
+	Attacks  int
+	OneStar  int
+	TwoStars int
+	Triples  int
+}
+
+func (t *Tally) add(stars int) {
+	t.Attacks++
+	if stars >= 1 {
+		t.OneStar++
+	}
+	if stars >= 2 {
+		t.TwoStars++
+	}
+	if stars >= 3 {
+		t.Triples++
+	}
+}
+
+func (t *Tally) merge(other *Tally) {
+	t.Attacks += other.Attacks
+	t.OneStar += other.OneStar
+	t.TwoStars += other.TwoStars
+	t.Triples += other.Triples
+}
+
+// TwoStar is the rate of attacks that earned at least two stars
+func (t *Tally) TwoStar() Rate {
+	return Rate{Hits: t.TwoStars, Attacks: t.Attacks}
+}
+
+// Triple is the rate of attacks that earned three stars
+func (t *Tally) Triple() Rate {
+	return Rate{Hits: t.Triples, Attacks: t.Attacks}
+}
+
+// Rates will convert the tally to the chance of earning at least one, two and
+// three stars
+func (t *Tally) Rates() StarRates {
+	if t.Attacks == 0 {
+		return StarRates{}
+	}
+	n := float64(t.Attacks)
+	return StarRates{float64(t.OneStar) / n, float64(t.TwoStars) / n, float64(t.Triples) / n}
+}
+
+// Matchup is an attacker town hall level against a defender town hall level
+type Matchup struct {
+	Attacker int
+	Defender int
+}
+
+// String formats the matchup like TH13v14
+func (m Matchup) String() string {
+	return fmt.Sprintf("TH%dv%d", m.Attacker, m.Defender)
+}
+
+// Model is a HitRateModel learned from the attacks in stored wars. The most
+// specific rates with enough attacks are used: the attacker's own rates for the
+// town hall difference, then the rates of the matchup, then the fallback
+type Model struct {
+	// Townhalls holds every attack by matchup
+	Townhalls map[Matchup]*Tally
+	// Players holds the attacks of each player by defender town hall minus
+	// attacker town hall, keyed by player tag
+	Players map[string]map[int]*Tally
+	// Armies holds the attacks made with each recorded army by matchup
+	Armies map[string]map[Matchup]*Tally
+	// Names holds the last known name of each player
+	Names map[string]string
+
+	// MinAttacks is the fewest attacks rates need before they are used
+	MinAttacks int
+	// Fallback is used when no rates have enough attacks, DefaultModel if nil
+	Fallback HitRateModel
+}
+
+// Learn will build a model from the attacks of both clans in the stored wars
+func Learn(records []*Record) *Model {
+	m := &Model{
+		Townhalls:  make(map[Matchup]*Tally),
+		Players:    make(map[string]map[int]*Tally),
+		Armies:     make(map[string]map[Matchup]*Tally),
+		Names:      make(map[string]string),
+		MinAttacks: DefaultMinAttacks,
+	}
+
+	for _, record := range records {
+		if record == nil || record.War == nil {
+			continue
+		}
+		townhalls := make(map[string]int)
+		for _, team := range [][]goclash.WarMember{record.War.Clan.Team, record.War.OpponentClan.Team} {
+			for _, member := range team {
+				townhalls[member.Tag] = member.TownhallLevel
+			}
+		}
+
+		for _, team := range [][]goclash.WarMember{record.War.Clan.Team, record.War.OpponentClan.Team} {
+			for _, member := range team {
+				m.Names[member.Tag] = member.Name
+				for _, attack := range member.Attacks {
+					defender, ok := townhalls[attack.DefenderTag]
+					if !ok {
+						continue
+					}
+					matchup := Matchup{Attacker: member.TownhallLevel, Defender: defender}
+					for _, t := range m.tallies(matchup, member.Tag, record.Armies[attack.Order]) {
+						t.add(attack.Stars)
+					}
+				}
+			}
+		}
+	}
+
+	return m
+}
+
+// tallies will get every tally an attack is counted in
+func (m *Model) tallies(matchup Matchup, tag, army string) []*Tally {
+	if m.Townhalls[matchup] == nil {
+		m.Townhalls[matchup] = &Tally{}
+	}
+	tallies := []*Tally{m.Townhalls[matchup]}
+
+	diff := matchup.Defender - matchup.Attacker
+	if m.Players[tag] == nil {
+		m.Players[tag] = make(map[int]*Tally)
+	}
+	if m.Players[tag][diff] == nil {
+		m.Players[tag][diff] = &Tally{}
+	}
+	tallies = append(tallies, m.Players[tag][diff])
+
+	if army != "" {
+		if m.Armies[army] == nil {
+			m.Armies[army] = make(map[Matchup]*Tally)
+		}
+		if m.Armies[army][matchup] == nil {
+			m.Armies[army][matchup] = &Tally{}
+		}
+		tallies = append(tallies, m.Armies[army][matchup])
+	}
+	return tallies
+}
+
+// Rates will get the hit rates of the attacker against the defender
+func (m *Model) Rates(attacker, defender *goclash.WarMember) StarRates {
+	diff := defender.TownhallLevel - attacker.TownhallLevel
+	if t, ok := m.Players[attacker.Tag][diff]; ok && t.Attacks >= m.MinAttacks {
+		return t.Rates()
+	}
+	matchup := Matchup{Attacker: attacker.TownhallLevel, Defender: defender.TownhallLevel}
+	if t, ok := m.Townhalls[matchup]; ok && t.Attacks >= m.MinAttacks {
+		return t.Rates()
+	}
+	if m.Fallback != nil {
+		return m.Fallback.Rates(attacker, defender)
+	}
+	return DefaultModel.Rates(attacker, defender)
+}
+
+// ArmyRates will get the hit rates of an army in a matchup, reporting false if the
+// army does not have enough attacks in the matchup
+func (m *Model) ArmyRates(army string, matchup Matchup) (StarRates, bool) {
+	if t, ok := m.Armies[army][matchup]; ok && t.Attacks >= m.MinAttacks {
+		return t.Rates(), true
+	}
+	return StarRates{}, false
+}
+
+// Player will get every attack a player made
+func (m *Model) Player(tag string) *Tally {
+	var total Tally
+	for _, t := range m.Players[tag] {
+		total.merge(t)
+	}
+	return &total
+}
+
+func sortedMatchups(tallies map[Matchup]*Tally) []Matchup {
+	matchups := make([]Matchup, 0, len(tallies))
+	for matchup := range tallies {
+		matchups = append(matchups, matchup)
+	}
+	sort.Slice(matchups, func(i, j int) bool {
+		if matchups[i].Attacker == matchups[j].Attacker {
+			return matchups[i].Defender < matchups[j].Defender
+		}
+		return matchups[i].Attacker > matchups[j].Attacker
+	})
+	return matchups
+}
+
+// WriteTable will write the rates by matchup, player and army as tables
+func (m *Model) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "Matchup\tAttacks\t2 Star\t3 Star")
+	for _, matchup := range sortedMatchups(m.Townhalls) {
+		t := m.Townhalls[matchup]
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", matchup, t.Attacks, t.TwoStar(), t.Triple())
+	}
+
+	tags := make([]string, 0, len(m.Players))
+	for tag := range m.Players {
+		if m.Player(tag).Attacks > 0 {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		ti, tj := m.Player(tags[i]), m.Player(tags[j])
+		if ti.Attacks == tj.Attacks {
+			return tags[i] < tags[j]
+		}
+		return ti.Attacks > tj.Attacks
+	})
+	fmt.Fprintln(tw, "\nPlayer\tAttacks\t2 Star\t3 Star")
+	for _, tag := range tags {
+		t := m.Player(tag)
+		fmt.Fprintf(tw, "%s (%s)\t%d\t%s\t%s\n", m.Names[tag], tag, t.Attacks, t.TwoStar(), t.Triple())
+	}
+
+	if len(m.Armies) > 0 {
+		armies := make([]string, 0, len(m.Armies))
+		for army := range m.Armies {
+			armies = append(armies, army)
+		}
+		sort.Strings(armies)
+		fmt.Fprintln(tw, "\nArmy\tMatchup\tAttacks\t2 Star\t3 Star")
+		for _, army := range armies {
+			for _, matchup := range sortedMatchups(m.Armies[army]) {
+				t := m.Armies[army][matchup]
+				fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", army, matchup, t.Attacks, t.TwoStar(), t.Triple())
+			}
+		}
+	}
+
+	return tw.Flush()
+}
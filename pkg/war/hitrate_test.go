@@ -0,0 +1,73 @@
+package war_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/war"
+)
+
+func TestModel(t *testing.T) {
+	var records []*war.Record
+	for i := 0; i < 10; i++ {
+		stars := 3
+		if i%2 == 1 {
+			stars = 2
+		}
+		records = append(records, &war.Record{
+			War: &goclash.War{
+				Clan: goclash.WarClan{Tag: "#CLAN", Team: []goclash.WarMember{
+					{Tag: "#A1", Name: "alpha", TownhallLevel: 13, Attacks: []goclash.Attack{
+						{Order: 1, AttackerTag: "#A1", DefenderTag: "#B1", Stars: stars},
+					}},
+				}},
+				OpponentClan: goclash.WarClan{Tag: "#OPP", Team: []goclash.WarMember{
+					{Tag: "#B1", Name: "bravo", TownhallLevel: 13},
+				}},
+			},
+			Armies: map[int]string{1: "zap dragons"},
+		})
+	}
+
+	// records without a war, e.g. from a hand edited history, are skipped
+	model := war.Learn(append(records, &war.Record{}, nil))
+	matchup := war.Matchup{Attacker: 13, Defender: 13}
+	tally := model.Townhalls[matchup]
+	if tally == nil || tally.Attacks != 10 || tally.Triples != 5 || tally.TwoStars != 10 {
+		t.Fatalf("unexpected tally for %s: %+v", matchup, tally)
+	}
+
+	triple := tally.Triple()
+	low, high := triple.Interval()
+	if triple.Value() != 0.5 || low >= 0.5 || high <= 0.5 || low < 0 || high > 1 {
+		t.Errorf("Wanted: 50%% inside its interval\tGot: %s", triple)
+	}
+
+	alpha, bravo := &goclash.WarMember{Tag: "#A1", TownhallLevel: 13},
+		&goclash.WarMember{Tag: "#B1", TownhallLevel: 13}
+	if rates := model.Rates(alpha, bravo); rates != (war.StarRates{1, 1, 0.5}) {
+		t.Errorf("Wanted: learned rates\tGot: %v", rates)
+	}
+	unknown := &goclash.WarMember{Tag: "#C1", TownhallLevel: 9}
+	if rates := model.Rates(unknown, bravo); rates != war.DefaultModel.Rates(unknown, bravo) {
+		t.Errorf("Wanted: fallback rates\tGot: %v", rates)
+	}
+	if _, ok := model.ArmyRates("zap dragons", matchup); !ok {
+		t.Error("the army should have rates for the matchup")
+	}
+	if model.Player("#A1").Attacks != 10 {
+		t.Errorf("Wanted: 10 attacks by alpha\tGot: %d", model.Player("#A1").Attacks)
+	}
+
+	var buf bytes.Buffer
+	if err := model.WriteTable(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"TH13v13", "alpha (#A1)", "zap dragons"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("report is missing %q:\n%s", want, buf.String())
+		}
+	}
+}
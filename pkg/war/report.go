@@ -0,0 +1,309 @@
+package war
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/joshturge/goclash/pkg/clash"
+)
+
+// DefaultAttacksPerMember is the amount of attacks each member gets in a regular
+// clan war. It is used when a war does not specify how many attacks are allowed
+const DefaultAttacksPerMember = 2
+
+// Report holds the per member performance of both clans in a war
+type Report struct {
+	State    string
+	TeamSize int
+	Clan     *ClanReport
+	Opponent *ClanReport
+}
+
+// ClanReport holds the performance of every member of a clan in a war
+type ClanReport struct {
+	Tag     string
+	Name    string
+	Members []*MemberReport
+}
+
+// MemberReport holds how a single member performed in a war
+type MemberReport struct {
+	Tag              string
+	Name             string
+	MapPosition      int
+	TownhallLevel    int
+	AttacksUsed      int
+	AttacksAvailable int
+	Stars            int
+	NewStars         int
+	Destruction      float32
+	Triples          int
+	HitsUp           int
+	HitsDown         int
+	HitsMirror       int
+	Attacks          []*AttackReport
+	Defence          DefenceReport
+}
+
+// AttackReport holds information about a single attack made by a member
+type AttackReport struct {
+	Order                 int
+	DefenderTag           string
+	DefenderName          string
+	DefenderMapPosition   int
+	DefenderTownhallLevel int
+	AttackerTownhallLevel int
+	Stars                 int
+	NewStars              int
+	Destruction           float32
+}
+
+// DefenceReport holds information on how a members base held up against the opponent
+type DefenceReport struct {
+	AttacksReceived int
+	Holds           int
+	BestStars       int
+	BestDestruction float32
+}
+
+// Triple reports whether the attack was a three star
+func (ar *AttackReport) Triple() bool {
+	return ar.Stars == 3
+}
+
+// TownhallDifference is the defenders townhall level minus the attackers. A positive
+// difference means the attacker hit a higher townhall
+func (ar *AttackReport) TownhallDifference() int {
+	return ar.DefenderTownhallLevel - ar.AttackerTownhallLevel
+}
+
+// Matchup formats the townhall levels of the attacker and defender, e.g. TH13v14
+func (ar *AttackReport) Matchup() string {
+	return fmt.Sprintf("TH%dv%d", ar.AttackerTownhallLevel, ar.DefenderTownhallLevel)
+}
+
+// AttacksRemaining is the amount of attacks a member has not used yet
+func (mr *MemberReport) AttacksRemaining() int {
+	if mr.AttacksUsed > mr.AttacksAvailable {
+		return 0
+	}
+	return mr.AttacksAvailable - mr.AttacksUsed
+}
+
+// AverageDestruction is the average destruction percentage of all attacks made
+func (mr *MemberReport) AverageDestruction() float32 {
+	if mr.AttacksUsed == 0 {
+		return 0
+	}
+	return mr.Destruction / float32(mr.AttacksUsed)
+}
+
+// Held reports whether a members base was attacked and not three starred
+func (dr *DefenceReport) Held() bool {
+	return dr.AttacksReceived > 0 && dr.BestStars < 3
+}
+
+// NewReport will compute the per member performance of both clans in a war
+func NewReport(w *goclash.War) *Report {
+	perMember := w.AttacksPerMember
+	if perMember == 0 {
+		perMember = DefaultAttacksPerMember
+	}
+
+	return &Report{
+		State:    w.State,
+		TeamSize: w.TeamSize,
+		Clan:     newClanReport(&w.Clan, &w.OpponentClan, perMember),
+		Opponent: newClanReport(&w.OpponentClan, &w.Clan, perMember),
+	}
+}
+
+func newClanReport(clan, opponent *goclash.WarClan, perMember int) *ClanReport {
+	defenders := make(map[string]*goclash.WarMember, len(opponent.Team))
+	for i := range opponent.Team {
+		defenders[opponent.Team[i].Tag] = &opponent.Team[i]
+	}
+
+	newStars := newStarsByAttack(clan.Team)
+	received := attacksByDefender(opponent.Team)
+
+	cr := ClanReport{
+		Tag:     clan.Tag,
+		Name:    clan.Name,
+		Members: make([]*MemberReport, 0, len(clan.Team)),
+	}
+
+	for i := range clan.Team {
+		member := &clan.Team[i]
+		mr := MemberReport{
+			Tag:              member.Tag,
+			Name:             member.Name,
+			MapPosition:      member.MapPosition,
+			TownhallLevel:    member.TownhallLevel,
+			AttacksUsed:      len(member.Attacks),
+			AttacksAvailable: perMember,
+			Attacks:          make([]*AttackReport, 0, len(member.Attacks)),
+		}
+
+		for _, attack := range member.Attacks {
+			ar := AttackReport{
+				Order:                 attack.Order,
+				DefenderTag:           attack.DefenderTag,
+				AttackerTownhallLevel: member.TownhallLevel,
+				Stars:                 attack.Stars,
+				NewStars:              newStars[attack.Order],
+				Destruction:           attack.DestructionPercentage,
+			}
+			if defender, ok := defenders[attack.DefenderTag]; ok {
+				ar.DefenderName = defender.Name
+				ar.DefenderMapPosition = defender.MapPosition
+				ar.DefenderTownhallLevel = defender.TownhallLevel
+
+				switch {
+				case defender.MapPosition < member.MapPosition:
+					mr.HitsUp++
+				case defender.MapPosition > member.MapPosition:
+					mr.HitsDown++
+				default:
+					mr.HitsMirror++
+				}
+			}
+
+			mr.Stars += ar.Stars
+			mr.NewStars += ar.NewStars
+			mr.Destruction += ar.Destruction
+			if ar.Triple() {
+				mr.Triples++
+			}
+			mr.Attacks = append(mr.Attacks, &ar)
+		}
+
+		mr.Defence.AttacksReceived = member.OpponentAttacks
+		if member.OpponentAttacks > 0 {
+			mr.Defence.BestStars = member.BestOpponentAttack.Stars
+			mr.Defence.BestDestruction = member.BestOpponentAttack.DestructionPercentage
+		}
+		for _, attack := range received[member.Tag] {
+			if attack.Stars < 3 {
+				mr.Defence.Holds++
+			}
+		}
+
+		cr.Members = append(cr.Members, &mr)
+	}
+
+	sort.Slice(cr.Members, func(i, j int) bool {
+		return cr.Members[i].MapPosition < cr.Members[j].MapPosition
+	})
+
+	return &cr
+}
+
+// attacksByDefender will group the attacks made by a team by the tag of the defender
+// in the order they were made
+func attacksByDefender(team []goclash.WarMember) map[string][]goclash.Attack {
+	byDefender := make(map[string][]goclash.Attack)
+	for _, member := range team {
+		for _, attack := range member.Attacks {
+			byDefender[attack.DefenderTag] = append(byDefender[attack.DefenderTag], attack)
+		}
+	}
+	for _, attacks := range byDefender {
+		sort.Slice(attacks, func(i, j int) bool {
+			return attacks[i].Order < attacks[j].Order
+		})
+	}
+	return byDefender
+}
+
+// newStarsByAttack will work out how many stars each attack added on top of the
+// attacks made against the same base before it, keyed by attack order
+func newStarsByAttack(team []goclash.WarMember) map[int]int {
+	newStars := make(map[int]int)
+	for _, attacks := range attacksByDefender(team) {
+		best := 0
+		for _, attack := range attacks {
+			if attack.Stars > best {
+				newStars[attack.Order] = attack.Stars - best
+				best = attack.Stars
+			} else {
+				newStars[attack.Order] = 0
+			}
+		}
+	}
+	return newStars
+}
+
+var reportColumns = []string{"#", "Name", "TH", "Attacks", "Stars", "New", "Avg %", "3*",
+	"Up/Mirror/Down", "Matchups", "Defence"}
+
+func (mr *MemberReport) columns() []string {
+	matchups := make([]string, 0, len(mr.Attacks))
+	for _, ar := range mr.Attacks {
+		matchups = append(matchups, fmt.Sprintf("%s %d*", ar.Matchup(), ar.Stars))
+	}
+
+	defence := "-"
+	if mr.Defence.AttacksReceived > 0 {
+		defence = fmt.Sprintf("%d* %.0f%% (%d/%d held)", mr.Defence.BestStars,
+			mr.Defence.BestDestruction, mr.Defence.Holds, mr.Defence.AttacksReceived)
+	}
+
+	return []string{
+		fmt.Sprintf("%d", mr.MapPosition),
+		mr.Name,
+		fmt.Sprintf("%d", mr.TownhallLevel),
+		fmt.Sprintf("%d/%d", mr.AttacksUsed, mr.AttacksAvailable),
+		fmt.Sprintf("%d", mr.Stars),
+		fmt.Sprintf("%d", mr.NewStars),
+		fmt.Sprintf("%.1f", mr.AverageDestruction()),
+		fmt.Sprintf("%d", mr.Triples),
+		fmt.Sprintf("%d/%d/%d", mr.HitsUp, mr.HitsMirror, mr.HitsDown),
+		strings.Join(matchups, ", "),
+		defence,
+	}
+}
+
+// WriteTable will write the clan report as a plain text table to w
+func (cr *ClanReport) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, strings.Join(reportColumns, "\t")); err != nil {
+		return fmt.Errorf("could not write table header: %s", err.Error())
+	}
+	for _, mr := range cr.Members {
+		if _, err := fmt.Fprintln(tw, strings.Join(mr.columns(), "\t")); err != nil {
+			return fmt.Errorf("could not write table row: %s", err.Error())
+		}
+	}
+	return tw.Flush()
+}
+
+// WriteMarkdown will write the clan report as a Markdown table to w
+func (cr *ClanReport) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("| ")
+	b.WriteString(strings.Join(reportColumns, " | "))
+	b.WriteString(" |\n|")
+	for range reportColumns {
+		b.WriteString(" --- |")
+	}
+	b.WriteString("\n")
+
+	for _, mr := range cr.Members {
+		columns := mr.columns()
+		for i, column := range columns {
+			columns[i] = strings.Replace(column, "|", "\\|", -1)
+		}
+		b.WriteString("| ")
+		b.WriteString(strings.Join(columns, " | "))
+		b.WriteString(" |\n")
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("could not write markdown table: %s", err.Error())
+	}
+	return nil
+}
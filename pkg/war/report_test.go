@@ -0,0 +1,104 @@
+package war_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/joshturge/goclash/pkg/clash"
+	"github.com/joshturge/goclash/pkg/war"
+)
+
+func testWar() *goclash.War {
+	return &goclash.War{
+		State:    "warEnded",
+		TeamSize: 2,
+		Clan: goclash.WarClan{
+			Tag:  "#CLAN",
+			Name: "Us",
+			Team: []goclash.WarMember{
+				{Tag: "#A1", Name: "alpha", MapPosition: 1, TownhallLevel: 13,
+					OpponentAttacks: 1,
+					BestOpponentAttack: goclash.Attack{Order: 2, AttackerTag: "#B2",
+						DefenderTag: "#A1", Stars: 2, DestructionPercentage: 80},
+					Attacks: []goclash.Attack{
+						{Order: 1, AttackerTag: "#A1", DefenderTag: "#B1", Stars: 2, DestructionPercentage: 90},
+					}},
+				{Tag: "#A2", Name: "bravo", MapPosition: 2, TownhallLevel: 12,
+					Attacks: []goclash.Attack{
+						{Order: 3, AttackerTag: "#A2", DefenderTag: "#B1", Stars: 3, DestructionPercentage: 100},
+						{Order: 4, AttackerTag: "#A2", DefenderTag: "#B2", Stars: 3, DestructionPercentage: 100},
+					}},
+			},
+		},
+		OpponentClan: goclash.WarClan{
+			Tag:  "#OPP",
+			Name: "Them",
+			Team: []goclash.WarMember{
+				{Tag: "#B1", Name: "charlie", MapPosition: 1, TownhallLevel: 14, OpponentAttacks: 2,
+					BestOpponentAttack: goclash.Attack{Order: 3, Stars: 3, DestructionPercentage: 100}},
+				{Tag: "#B2", Name: "delta", MapPosition: 2, TownhallLevel: 12, OpponentAttacks: 1,
+					BestOpponentAttack: goclash.Attack{Order: 4, Stars: 3, DestructionPercentage: 100},
+					Attacks: []goclash.Attack{
+						{Order: 2, AttackerTag: "#B2", DefenderTag: "#A1", Stars: 2, DestructionPercentage: 80},
+					}},
+			},
+		},
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	report := war.NewReport(testWar())
+
+	if len(report.Clan.Members) != 2 {
+		t.Fatalf("Wanted: 2 members\tGot: %d", len(report.Clan.Members))
+	}
+
+	alpha := report.Clan.Members[0]
+	if alpha.AttacksUsed != 1 || alpha.AttacksAvailable != war.DefaultAttacksPerMember {
+		t.Errorf("Wanted: 1/%d attacks\tGot: %d/%d", war.DefaultAttacksPerMember,
+			alpha.AttacksUsed, alpha.AttacksAvailable)
+	}
+	if alpha.Attacks[0].Matchup() != "TH13v14" {
+		t.Errorf("Wanted: TH13v14\tGot: %s", alpha.Attacks[0].Matchup())
+	}
+	if !alpha.Defence.Held() || alpha.Defence.Holds != 1 {
+		t.Errorf("alpha's base should have held once, got %+v", alpha.Defence)
+	}
+
+	bravo := report.Clan.Members[1]
+	if bravo.Stars != 6 || bravo.NewStars != 4 {
+		t.Errorf("Wanted: 6 stars, 4 new\tGot: %d stars, %d new", bravo.Stars, bravo.NewStars)
+	}
+	if bravo.Triples != 2 || bravo.HitsUp != 1 || bravo.HitsMirror != 1 {
+		t.Errorf("unexpected hit breakdown: %+v", bravo)
+	}
+
+	charlie := report.Opponent.Members[0]
+	if charlie.AttacksUsed != 0 || charlie.AttacksRemaining() != 2 {
+		t.Errorf("Wanted: 2 remaining attacks\tGot: %d", charlie.AttacksRemaining())
+	}
+	if charlie.Defence.Held() {
+		t.Error("charlie's base was tripled and should not have held")
+	}
+}
+
+func TestReportRender(t *testing.T) {
+	report := war.NewReport(testWar())
+
+	var table bytes.Buffer
+	if err := report.Clan.WriteTable(&table); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(table.String(), "bravo") {
+		t.Errorf("table is missing a member:\n%s", table.String())
+	}
+
+	var md bytes.Buffer
+	if err := report.Clan.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(md.String(), "\n"); lines != 4 {
+		t.Errorf("Wanted: 4 markdown lines\tGot: %d\n%s", lines, md.String())
+	}
+}